@@ -0,0 +1,99 @@
+package gotpasswd
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Number of words of BIP39 wordlists, each word encodes 11 bits
+const mnemonicWordlistSize = 2048
+
+// The official English wordlist of BIP39, used when Config.MnemonicWordlist is nil
+//
+//go:embed bip39_english.txt
+var embeddedMnemonicWordlist string
+
+var mnemonicWordlist = strings.Fields(embeddedMnemonicWordlist)
+
+// validMnemonicLength returns whether n words are a BIP39 mnemonic, of 128 to 256 bits of entropy.
+func validMnemonicLength(n int) bool {
+	return n >= 12 && n <= 24 && n%3 == 0
+}
+
+// validateMnemonicWordlist checks the wordlist has exactly the words of BIP39, without duplicates.
+func validateMnemonicWordlist(words []string) error {
+	if len(words) != mnemonicWordlistSize {
+		return errors.New(fmt.Sprintf("BIP39 wordlist must have %d words, not %d", mnemonicWordlistSize, len(words)))
+	}
+	seen := make(map[string]bool)
+	for _, word := range words {
+		if seen[word] {
+			return errors.New(fmt.Sprintf("BIP39 wordlist has duplicated word: %s", word))
+		}
+		seen[word] = true
+	}
+	return nil
+}
+
+// VerifyMnemonicWordlist compares words with the official English wordlist of BIP39.
+// It returns true if they are same, or an error if most of them are English words but they differ,
+// as mnemonics of them are not restored by wallets. Wordlists of other languages cannot be verified, and false is returned.
+func VerifyMnemonicWordlist(words []string) (bool, error) {
+	if slices.Equal(words, mnemonicWordlist) {
+		return true, nil
+	}
+	english := 0
+	for _, word := range words {
+		if _, found := slices.BinarySearch(mnemonicWordlist, word); found {
+			english++
+		}
+	}
+	if english*2 <= len(words) {
+		return false, nil
+	}
+	for i, word := range words {
+		if i >= len(mnemonicWordlist) || word != mnemonicWordlist[i] {
+			return false, errors.New(fmt.Sprintf("BIP39 wordlist differs from the official English one at word %d: %s", i+1, word))
+		}
+	}
+	return false, errors.New(fmt.Sprintf("BIP39 wordlist lacks words of the official English one from word %d", len(words)+1))
+}
+
+func (self *Config) mnemonicWordlist() []string {
+	if self.MnemonicWordlist == nil {
+		return mnemonicWordlist
+	}
+	return self.MnemonicWordlist
+}
+
+// generateMnemonic draws the entropy of a BIP39 mnemonic, and encodes it with the checksum of SHA-256 by the wordlist.
+// Words are separated by spaces, ideographic ones for the japanese wordlist as BIP39 specifies.
+func generateMnemonic(r io.Reader, config *Config) ([]rune, error) {
+	// entropy is 32 bits for each 3 words, and the checksum is 1 bit for each 32 bits
+	entropy := make([]byte, config.Mnemonic*4/3)
+	if _, err := io.ReadFull(r, entropy); err != nil {
+		return nil, errors.New(fmt.Sprintf("Cannot read randomness: %s", err))
+	}
+	hash := sha256.Sum256(entropy)
+	bits := append(entropy, hash[0])
+
+	words := make([]string, config.Mnemonic)
+	for i := range words {
+		index := 0
+		for bit := i * 11; bit < (i+1)*11; bit++ {
+			index = index<<1 | int(bits[bit/8]>>(7-bit%8)&1)
+		}
+		words[i] = config.mnemonicWordlist()[index]
+	}
+	separator := " "
+	if first := []rune(words[0]); unicode.Is(unicode.Hiragana, first[0]) {
+		separator = "　"
+	}
+	return []rune(strings.Join(words, separator)), nil
+}
@@ -0,0 +1,56 @@
+package gotpasswd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestGenerateMnemonic(t *testing.T) {
+	// test vectors of the reference implementation
+	for _, v := range []struct {
+		entropy  []byte
+		mnemonic string
+	}{
+		{bytes.Repeat([]byte{0x00}, 16), strings.Repeat("abandon ", 11) + "about"},
+		{bytes.Repeat([]byte{0x7f}, 16), "legal winner thank year wave sausage worth useful legal winner thank yellow"},
+		{bytes.Repeat([]byte{0x80}, 16), "letter advice cage absurd amount doctor acoustic avoid letter advice cage above"},
+		{bytes.Repeat([]byte{0xff}, 16), strings.Repeat("zoo ", 11) + "wrong"},
+		{bytes.Repeat([]byte{0x00}, 32), strings.Repeat("abandon ", 23) + "art"},
+		{bytes.Repeat([]byte{0xff}, 32), strings.Repeat("zoo ", 23) + "vote"},
+	} {
+		config := &Config{Length: 1, Mnemonic: len(v.entropy) * 3 / 4}
+		if err := config.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		mnemonic, err := generateMnemonic(bytes.NewReader(v.entropy), config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(mnemonic) != v.mnemonic {
+			t.Errorf("mnemonic of %x = %q, want %q", v.entropy, string(mnemonic), v.mnemonic)
+		}
+	}
+}
+
+func TestVerifyMnemonicWordlist(t *testing.T) {
+	words := append([]string{}, mnemonicWordlist...)
+	if official, err := VerifyMnemonicWordlist(words); !official || err != nil {
+		t.Errorf("the embedded wordlist is not official: %v", err)
+	}
+	words[100] = "typo"
+	if _, err := VerifyMnemonicWordlist(words); err == nil {
+		t.Error("a wrong English wordlist is accepted")
+	}
+	if _, err := VerifyMnemonicWordlist(mnemonicWordlist[:2047]); err == nil {
+		t.Error("a short English wordlist is accepted")
+	}
+	other := make([]string, mnemonicWordlistSize)
+	for i := range other {
+		other[i] = fmt.Sprintf("単語%d", i)
+	}
+	if official, err := VerifyMnemonicWordlist(other); official || err != nil {
+		t.Errorf("wordlists of other languages cannot be verified: %v, %v", official, err)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// StrengthLabel names the strength of the entropy in bits.
+func StrengthLabel(entropy float64) string {
+	switch {
+	case entropy < 28:
+		return "very weak"
+	case entropy < 36:
+		return "weak"
+	case entropy < 60:
+		return "reasonable"
+	case entropy < 128:
+		return "strong"
+	default:
+		return "very strong"
+	}
+}
+
+func strengthColor(entropy float64) string {
+	switch {
+	case entropy < 36:
+		return "#e05d44"
+	case entropy < 60:
+		return "#dfb317"
+	default:
+		return "#4c1"
+	}
+}
+
+// WriteBadge writes a shields.io style SVG badge showing the entropy and its strength.
+func WriteBadge(w io.Writer, entropy float64) error {
+	const label = "password entropy"
+	message := fmt.Sprintf("%.1f bits, %s", entropy, StrengthLabel(entropy))
+	// approximation of text widths in 11px Verdana
+	labelWidth := len(label)*7 + 10
+	messageWidth := len(message)*7 + 10
+	width := labelWidth + messageWidth
+
+	_, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">
+  <title>%s: %s</title>
+  <rect width="%d" height="20" fill="#555"/>
+  <rect x="%d" width="%d" height="20" fill="%s"/>
+  <g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+    <text x="%d" y="14">%s</text>
+    <text x="%d" y="14">%s</text>
+  </g>
+</svg>
+`,
+		width, xmlEscape(label), xmlEscape(message),
+		xmlEscape(label), xmlEscape(message),
+		labelWidth,
+		labelWidth, messageWidth, strengthColor(entropy),
+		labelWidth/2, xmlEscape(label),
+		labelWidth+messageWidth/2, xmlEscape(message))
+	return err
+}
+
+func xmlEscape(s string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
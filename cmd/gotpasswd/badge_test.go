@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWriteBadge(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteBadge(&b, 77.54); err != nil {
+		t.Fatal(err)
+	}
+	svg := b.String()
+
+	decoder := xml.NewDecoder(strings.NewReader(svg))
+	root := ""
+	texts := make([]string, 0)
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("badge is not well-formed XML: %s\n%s", err, svg)
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if root == "" {
+				root = token.Name.Local
+			}
+			inText = token.Name.Local == "text"
+		case xml.CharData:
+			if inText {
+				texts = append(texts, string(token))
+			}
+		case xml.EndElement:
+			inText = false
+		}
+	}
+	if root != "svg" {
+		t.Errorf("root element is %q, want svg", root)
+	}
+	if want := "77.5 bits, strong"; !strings.Contains(strings.Join(texts, "\n"), want) {
+		t.Errorf("texts %q do not contain %q", texts, want)
+	}
+}
+
+func TestStrengthLabel(t *testing.T) {
+	for entropy, want := range map[float64]string{
+		0:   "very weak",
+		30:  "weak",
+		59:  "reasonable",
+		60:  "strong",
+		128: "very strong",
+	} {
+		if label := StrengthLabel(entropy); label != want {
+			t.Errorf("StrengthLabel(%v) = %q, want %q", entropy, label, want)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Clearing after the timeout is done by a detached copy of the command, given "seconds:sha256[:osc52]" by the env
+const clipboardClearEnv = "GOTPASSWD_CLEAR_CLIPBOARD"
+
+// Default seconds of -copy until the clipboard is cleared
+const defaultCopySeconds = 45
+
+// copyFlag is a flag.Value of -copy, given alone for the default seconds or as -copy=SECONDS.
+type copyFlag struct {
+	seconds int
+}
+
+func (self *copyFlag) String() string {
+	if self == nil || self.seconds == 0 {
+		return ""
+	}
+	return strconv.Itoa(self.seconds)
+}
+
+func (self *copyFlag) Set(s string) error {
+	switch s {
+	case "true":
+		self.seconds = defaultCopySeconds
+		return nil
+	case "false":
+		self.seconds = 0
+		return nil
+	}
+	seconds, err := strconv.Atoi(s)
+	if err != nil || seconds <= 0 {
+		return errors.New(fmt.Sprintf("Seconds must be positive: %s", s))
+	}
+	self.seconds = seconds
+	return nil
+}
+
+func (self *copyFlag) IsBoolFlag() bool {
+	return true
+}
+
+type clipboardTool struct {
+	copy  []string
+	paste []string
+	// clear runs copy with an empty input if nil
+	clear []string
+}
+
+// clipboardTools lists clipboard commands of the platform, in the order of preference.
+func clipboardTools() []clipboardTool {
+	switch runtime.GOOS {
+	case "darwin":
+		return []clipboardTool{{copy: []string{"pbcopy"}, paste: []string{"pbpaste"}}}
+	case "windows":
+		return []clipboardTool{{copy: []string{"clip.exe"}, paste: []string{"powershell.exe", "-NoProfile", "-Command", "Get-Clipboard"}}}
+	}
+	tools := make([]clipboardTool, 0)
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		tools = append(tools, clipboardTool{copy: []string{"wl-copy"}, paste: []string{"wl-paste", "-n"}, clear: []string{"wl-copy", "--clear"}})
+	}
+	if os.Getenv("DISPLAY") != "" {
+		tools = append(tools,
+			clipboardTool{copy: []string{"xclip", "-selection", "clipboard"}, paste: []string{"xclip", "-selection", "clipboard", "-o"}},
+			clipboardTool{copy: []string{"xsel", "--clipboard", "--input"}, paste: []string{"xsel", "--clipboard", "--output"}, clear: []string{"xsel", "--clipboard", "--delete"}})
+	}
+	return tools
+}
+
+// errNoClipboard is returned when none of clipboard commands is available.
+var errNoClipboard = errors.New("No clipboard is available, such as wl-copy, xclip, xsel, pbcopy, clip.exe nor a terminal for OSC 52")
+
+func findClipboardTool() (clipboardTool, error) {
+	for _, tool := range clipboardTools() {
+		if _, err := exec.LookPath(tool.copy[0]); err == nil {
+			return tool, nil
+		}
+	}
+	return clipboardTool{}, errNoClipboard
+}
+
+func runClipboard(args []string, input string) error {
+	cmd := exec.Command(args[0], args[1:]...)
+	// outputs are not piped, commands like xclip keep serving the selection in background
+	cmd.Stdin = strings.NewReader(input)
+	return cmd.Run()
+}
+
+// osc52 returns the escape sequence setting the clipboard of the terminal emulator, which works over SSH.
+// tmux passes it to the outer terminal only in its passthrough sequence, with ESC doubled.
+func osc52(text string) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if os.Getenv("TMUX") != "" {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	return seq
+}
+
+// writeTerminal writes the escape sequence to the controlling terminal, which may not be stdout.
+func writeTerminal(seq string) error {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer tty.Close()
+	_, err = tty.WriteString(seq)
+	return err
+}
+
+func clipboardDigest(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// CopyToClipboard puts the text on the clipboard, and starts a detached process clearing it after seconds.
+// Without clipboard commands, such as over SSH, the terminal emulator is asked by OSC 52.
+func CopyToClipboard(text string, seconds int) error {
+	spec := fmt.Sprintf("%d:%s", seconds, clipboardDigest(text))
+	if tool, err := findClipboardTool(); err == nil {
+		if err := runClipboard(tool.copy, text); err != nil {
+			return err
+		}
+	} else if err := writeTerminal(osc52(text)); err == nil {
+		spec += ":osc52"
+	} else {
+		return errNoClipboard
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(exe)
+	cmd.Env = append(os.Environ(), clipboardClearEnv+"="+spec)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
+
+// clearClipboardLater is the detached process of CopyToClipboard, it clears the clipboard unless it was replaced.
+// The clipboard is cleared anyway if it cannot be read, as the clipboard of OSC 52.
+func clearClipboardLater(spec string) int {
+	pair := strings.SplitN(spec, ":", 3)
+	seconds, err := strconv.Atoi(pair[0])
+	if err != nil || len(pair) < 2 {
+		return 128
+	}
+	// survive the terminal closed meanwhile
+	signal.Ignore(syscall.SIGHUP, os.Interrupt)
+	time.Sleep(time.Duration(seconds) * time.Second)
+
+	if len(pair) == 3 && pair[2] == "osc52" {
+		if err := writeTerminal(osc52("")); err != nil {
+			return 1
+		}
+		return 0
+	}
+
+	tool, err := findClipboardTool()
+	if err != nil {
+		return 1
+	}
+	var out bytes.Buffer
+	paste := exec.Command(tool.paste[0], tool.paste[1:]...)
+	paste.Stdout = &out
+	if err := paste.Run(); err == nil && clipboardDigest(strings.TrimRight(out.String(), "\r\n")) != pair[1] {
+		return 0
+	}
+	if tool.clear != nil {
+		err = runClipboard(tool.clear, "")
+	} else {
+		err = runClipboard(tool.copy, "")
+	}
+	if err != nil {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/kamichidu/go-gotpasswd"
+)
+
+// ANSI SGR parameters for each character kind
+var kindColors = map[gotpasswd.CharacterKind]string{
+	gotpasswd.UPPER:      "1;34",
+	gotpasswd.LOWER:      "32",
+	gotpasswd.NUMBER:     "33",
+	gotpasswd.SYMBOL:     "35",
+	gotpasswd.UNDERSCORE: "36",
+	// reverse video, to make spaces visible
+	gotpasswd.SPACE: "7",
+}
+
+// Colorize wraps each character of s by the color of its kind.
+func Colorize(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		kind, ok := gotpasswd.KindOf(r)
+		// punctuation such as '!' and '#' is not of the symbol kind, but looks like symbols
+		if !ok && unicode.IsPunct(r) {
+			kind, ok = gotpasswd.SYMBOL, true
+		}
+		// emoji are left as is, they have their own colors
+		color, colored := kindColors[kind]
+		if !ok || !colored {
+			b.WriteRune(r)
+			continue
+		}
+		b.WriteString("\x1b[" + color + "m")
+		b.WriteRune(r)
+		b.WriteString("\x1b[0m")
+	}
+	return b.String()
+}
+
+// colorSupported reports whether file is a terminal and colors are not disabled by NO_COLOR.
+func colorSupported(file *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice != 0
+}
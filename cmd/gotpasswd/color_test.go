@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestColorize(t *testing.T) {
+	got := Colorize("aB3!_ é")
+	want := "\x1b[32ma\x1b[0m" +
+		"\x1b[1;34mB\x1b[0m" +
+		"\x1b[33m3\x1b[0m" +
+		"\x1b[35m!\x1b[0m" +
+		"\x1b[36m_\x1b[0m" +
+		"\x1b[7m \x1b[0m" +
+		"é"
+	if got != want {
+		t.Errorf("Colorize = %q, want %q", got, want)
+	}
+}
+
+func TestColorSupported(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if colorSupported(file) {
+		t.Error("colors must be omitted for files")
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	if colorSupported(w) {
+		t.Error("colors must be omitted for pipes")
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		t.Skip("no terminal:", err)
+	}
+	defer tty.Close()
+	if !colorSupported(tty) {
+		t.Error("colors must be enabled for terminals")
+	}
+	t.Setenv("NO_COLOR", "1")
+	if colorSupported(tty) {
+		t.Error("colors must be disabled by NO_COLOR")
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Short flags sharing values of other flags
+var flagAliases = map[string]string{
+	"0": "print0",
+	"w": "words",
+}
+
+type subcommand struct {
+	name  string
+	usage string
+	// flags implied by the subcommand, overridden by ones given explicitly
+	implies map[string]string
+}
+
+// Subcommands share the flags, gotpasswd without them is same as generate
+var subcommands = []subcommand{
+	{"generate", "Generate passwords, same as without subcommands", nil},
+	{"passphrase", "Generate passphrases of -words from -wordlist, 6 words of eff-long by default", map[string]string{"words": "6", "wordlist": "eff-long"}},
+	{"pin", "Generate PINs of -pin digits, 6 digits by default", map[string]string{"pin": "6"}},
+	{"token", "Generate API tokens of -token", map[string]string{"token": "true"}},
+	{"uuid", "Generate UUIDs of the version v4 or v7 of the argument or -uuid, v4 by default", map[string]string{"uuid": "v4"}},
+	{"ulid", "Generate ULIDs, same as -ulid", map[string]string{"ulid": "true"}},
+	{"validate", "Check passwords of lines of stdin against -policy or -policy-preset, exits 1 on violations", nil},
+	{"check", "Estimate strength of passwords of arguments or lines of stdin, same as -check", map[string]string{"check": "true"}},
+	{"hash", "Hash passwords of arguments or lines of stdin by PBKDF2-SHA256 in the PHC string format", nil},
+	{"serve", "Serve passwords generated by the flags over HTTP on -listen, ?n= for the number", nil},
+	{"charset", "Print the candidate characters of the flags", nil},
+	{"tui", "Generate passwords interactively on the terminal", nil},
+	{"config", "Write a starter config file of commented defaults by config init, overwriting it by -force", nil},
+	{"completion", "Print the completion script of the shell (bash, zsh, fish, powershell)", nil},
+}
+
+func lookupSubcommand(name string) (subcommand, bool) {
+	for _, command := range subcommands {
+		if command.name == name {
+			return command, true
+		}
+	}
+	return subcommand{}, false
+}
+
+// Flags given explicitly by args
+var commandLineFlags = make(map[string]bool)
+
+// Sources of flags set by environment variables or the config file, by names of flags
+var flagSources = make(map[string]string)
+
+func recordFlagSources(set map[string]bool, source string) {
+	for name := range set {
+		if _, ok := flagSources[name]; !ok && !commandLineFlags[name] {
+			flagSources[name] = source
+		}
+	}
+}
+
+// parseCommand parses flags following the subcommand of args, and returns the name of the subcommand.
+// Flags are set by args, environment variables, the config file and the subcommand in order of precedence,
+// each of them sets flags which are not set by preceding ones.
+func parseCommand(args []string) (string, error) {
+	command, ok := subcommand{name: "generate"}, false
+	if len(args) > 0 {
+		if command, ok = lookupSubcommand(args[0]); ok {
+			args = args[1:]
+		} else {
+			command = subcommand{name: "generate"}
+		}
+	}
+	// flags may follow the version, such as uuid v7 -n 2
+	var version string
+	if command.name == "uuid" && len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		version, args = args[0], args[1:]
+	}
+	if err := flag.CommandLine.Parse(args); err != nil {
+		return "", err
+	}
+	if version != "" {
+		if err := flag.CommandLine.Set("uuid", version); err != nil {
+			return "", err
+		}
+	}
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		commandLineFlags[f.Name] = true
+		set[f.Name] = true
+		if name, ok := flagAliases[f.Name]; ok {
+			commandLineFlags[name] = true
+			set[name] = true
+		}
+	})
+	if command.name != "config" {
+		if err := LoadEnv(flag.CommandLine, os.Environ(), set); err != nil {
+			return "", err
+		}
+		recordFlagSources(set, "an environment variable")
+		// -no-config may be given by GOTPASSWD_NO_CONFIG
+		if path, err := configFilePath(); err == nil && !*noConfig {
+			if err := LoadConfigFile(flag.CommandLine, path, set); err != nil {
+				return "", err
+			}
+			recordFlagSources(set, path)
+		}
+	}
+	for name, value := range command.implies {
+		if !set[name] {
+			if err := flag.Lookup(name).Value.Set(value); err != nil {
+				return "", err
+			}
+		}
+	}
+	return command.name, nil
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [subcommand] [flags]\n\nSubcommands:\n", os.Args[0])
+	for _, command := range subcommands {
+		fmt.Fprintf(out, "  %s\n    \t%s\n", command.name, command.usage)
+	}
+	fmt.Fprintln(out, "\nFlags:")
+	flag.PrintDefaults()
+}
+
+// readPasswords returns passwords of arguments, or lines of stdin without arguments.
+func readPasswords() ([]string, error) {
+	passwords := flag.Args()
+	if len(passwords) > 0 {
+		return passwords, nil
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		passwords = append(passwords, scanner.Text())
+	}
+	return passwords, scanner.Err()
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/kamichidu/go-gotpasswd"
+)
+
+// Shells of completion scripts, in order
+var completionShellNames = []string{"bash", "zsh", "fish", "powershell"}
+
+var completionShells = map[string]func(w io.Writer, spec *completionSpec){
+	"bash":       writeBashCompletion,
+	"zsh":        writeZshCompletion,
+	"fish":       writeFishCompletion,
+	"powershell": writePowerShellCompletion,
+}
+
+// completionSpec is what completion scripts complete, built from the live flags and dictionaries.
+type completionSpec struct {
+	Commands []subcommand
+	Flags    []*flag.Flag
+	// Values of flags by name
+	Values map[string][]string
+	// Flags whose values are comma separated
+	Lists map[string]bool
+	// Arguments of subcommands by name
+	Args map[string][]string
+}
+
+func newCompletionSpec() *completionSpec {
+	description := gotpasswd.Describe()
+	kinds := make([]string, 0, len(description.Kinds))
+	for _, kind := range description.Kinds {
+		kinds = append(kinds, kind.Name)
+	}
+	sort.Strings(kinds)
+	presets := gotpasswd.ProfileNames()
+	spec := &completionSpec{
+		Commands: subcommands,
+		Values: map[string][]string{
+			"k":             kinds,
+			"preset":        append(append([]string{}, presets...), "list"),
+			"gen":           presets,
+			"policy-preset": presets,
+			"format":        {"text", "json", "jsonl", "csv"},
+			"safe":          gotpasswd.UnsafeContexts(),
+		},
+		Lists: map[string]bool{"k": true, "safe": true},
+		Args:  map[string][]string{"completion": completionShellNames, "config": {"init"}, "uuid": {"v4", "v7"}},
+	}
+	flag.VisitAll(func(f *flag.Flag) {
+		if f.Name != "debug" {
+			spec.Flags = append(spec.Flags, f)
+		}
+	})
+	return spec
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	v, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && v.IsBoolFlag()
+}
+
+func (self *completionSpec) commandNames() []string {
+	names := make([]string, 0, len(self.Commands))
+	for _, command := range self.Commands {
+		names = append(names, command.name)
+	}
+	return names
+}
+
+func (self *completionSpec) flagNames() []string {
+	names := make([]string, 0, len(self.Flags))
+	for _, f := range self.Flags {
+		names = append(names, "-"+f.Name)
+	}
+	return names
+}
+
+// valueNames returns names of flags with values in order.
+func (self *completionSpec) valueNames() []string {
+	return sortedKeys(self.Values)
+}
+
+// WriteCompletion writes the completion script of the shell.
+func WriteCompletion(w io.Writer, shell string) error {
+	write, ok := completionShells[shell]
+	if !ok {
+		return errors.New(fmt.Sprintf("Unknown shell: %s, one of %s", shell, strings.Join(completionShellNames, ", ")))
+	}
+	write(w, newCompletionSpec())
+	return nil
+}
+
+func writeBashCompletion(w io.Writer, spec *completionSpec) {
+	fmt.Fprintln(w, "# bash completion of gotpasswd, source <(gotpasswd completion bash)")
+	fmt.Fprintln(w, "_gotpasswd() {")
+	fmt.Fprintln(w, `	local cur="${COMP_WORDS[COMP_CWORD]}" prev="${COMP_WORDS[COMP_CWORD-1]}"`)
+	fmt.Fprintln(w, `	case "$prev" in`)
+	for _, name := range spec.valueNames() {
+		values := strings.Join(spec.Values[name], " ")
+		if spec.Lists[name] {
+			fmt.Fprintf(w, "\t-%s)\n", name)
+			fmt.Fprintln(w, `		local prefix=""`)
+			fmt.Fprintln(w, `		[[ "$cur" == *,* ]] && prefix="${cur%,*},"`)
+			fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -P \"$prefix\" -W %s -- \"${cur##*,}\"))\n", shellQuote(values))
+			fmt.Fprintln(w, "\t\treturn;;")
+		} else {
+			fmt.Fprintf(w, "\t-%s)\n\t\tCOMPREPLY=($(compgen -W %s -- \"$cur\"))\n\t\treturn;;\n", name, shellQuote(values))
+		}
+	}
+	for _, name := range sortedKeys(spec.Args) {
+		fmt.Fprintf(w, "\t%s)\n\t\tCOMPREPLY=($(compgen -W %s -- \"$cur\"))\n\t\treturn;;\n", name, shellQuote(strings.Join(spec.Args[name], " ")))
+	}
+	fmt.Fprintln(w, "\tesac")
+	fmt.Fprintln(w, `	if [[ "$cur" == -* ]]; then`)
+	fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W %s -- \"$cur\"))\n", shellQuote(strings.Join(spec.flagNames(), " ")))
+	fmt.Fprintln(w, "\telif [[ $COMP_CWORD -eq 1 ]]; then")
+	fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W %s -- \"$cur\"))\n", shellQuote(strings.Join(spec.commandNames(), " ")))
+	fmt.Fprintln(w, "\tfi")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, "complete -o default -F _gotpasswd gotpasswd")
+}
+
+func writeZshCompletion(w io.Writer, spec *completionSpec) {
+	fmt.Fprintln(w, "#compdef gotpasswd")
+	fmt.Fprintln(w, "# zsh completion of gotpasswd, source <(gotpasswd completion zsh) or put it as _gotpasswd in fpath")
+	fmt.Fprintln(w, "_gotpasswd() {")
+	fmt.Fprintln(w, "\tlocal -a commands flags")
+	fmt.Fprintln(w, "\tcommands=(")
+	for _, command := range spec.Commands {
+		fmt.Fprintf(w, "\t\t%s\n", shellQuote(command.name+":"+command.usage))
+	}
+	fmt.Fprintln(w, "\t)")
+	fmt.Fprintln(w, "\tflags=(")
+	for _, f := range spec.Flags {
+		fmt.Fprintf(w, "\t\t%s\n", shellQuote("-"+f.Name+":"+flagUsage(f)))
+	}
+	fmt.Fprintln(w, "\t)")
+	fmt.Fprintln(w, "\tcase $words[CURRENT-1] in")
+	for _, name := range spec.valueNames() {
+		values := strings.Join(spec.Values[name], " ")
+		if spec.Lists[name] {
+			fmt.Fprintf(w, "\t-%s)\n\t\t_values -s , %s %s\n\t\treturn;;\n", name, name, values)
+		} else {
+			fmt.Fprintf(w, "\t-%s)\n\t\tcompadd -- %s\n\t\treturn;;\n", name, values)
+		}
+	}
+	for _, name := range sortedKeys(spec.Args) {
+		fmt.Fprintf(w, "\t%s)\n\t\tcompadd -- %s\n\t\treturn;;\n", name, strings.Join(spec.Args[name], " "))
+	}
+	fmt.Fprintln(w, "\tesac")
+	fmt.Fprintln(w, "\tif [[ $words[CURRENT] == -* ]]; then")
+	fmt.Fprintln(w, "\t\t_describe flag flags")
+	fmt.Fprintln(w, "\telif (( CURRENT == 2 )); then")
+	fmt.Fprintln(w, "\t\t_describe command commands")
+	fmt.Fprintln(w, "\telse")
+	fmt.Fprintln(w, "\t\t_files")
+	fmt.Fprintln(w, "\tfi")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, `if [ "$funcstack[1]" = "_gotpasswd" ]; then`)
+	fmt.Fprintln(w, `	_gotpasswd "$@"`)
+	fmt.Fprintln(w, "else")
+	fmt.Fprintln(w, "	compdef _gotpasswd gotpasswd")
+	fmt.Fprintln(w, "fi")
+}
+
+func writeFishCompletion(w io.Writer, spec *completionSpec) {
+	fmt.Fprintln(w, "# fish completion of gotpasswd, gotpasswd completion fish | source")
+	fmt.Fprintln(w, "complete -c gotpasswd -e")
+	for _, command := range spec.Commands {
+		fmt.Fprintf(w, "complete -c gotpasswd -f -n __fish_use_subcommand -a %s -d %s\n", command.name, shellQuote(command.usage))
+	}
+	for _, name := range sortedKeys(spec.Args) {
+		fmt.Fprintf(w, "complete -c gotpasswd -f -n '__fish_seen_subcommand_from %s' -a %s\n", name, shellQuote(strings.Join(spec.Args[name], " ")))
+	}
+	for _, f := range spec.Flags {
+		args := ""
+		if values, ok := spec.Values[f.Name]; ok {
+			if spec.Lists[f.Name] {
+				args = " -x -a " + shellQuote("(__fish_complete_list , \"string split ' ' '"+strings.Join(values, " ")+"'\")")
+			} else {
+				args = " -x -a " + shellQuote(strings.Join(values, " "))
+			}
+		} else if !isBoolFlag(f) {
+			args = " -r"
+		}
+		fmt.Fprintf(w, "complete -c gotpasswd -o %s%s -d %s\n", f.Name, args, shellQuote(flagUsage(f)))
+	}
+}
+
+func writePowerShellCompletion(w io.Writer, spec *completionSpec) {
+	quote := func(values []string) string {
+		quoted := make([]string, len(values))
+		for i, value := range values {
+			quoted[i] = "'" + strings.ReplaceAll(value, "'", "''") + "'"
+		}
+		return "@(" + strings.Join(quoted, ", ") + ")"
+	}
+	fmt.Fprintln(w, "# PowerShell completion of gotpasswd, gotpasswd completion powershell | Out-String | Invoke-Expression")
+	fmt.Fprintln(w, "Register-ArgumentCompleter -Native -CommandName gotpasswd -ScriptBlock {")
+	fmt.Fprintln(w, "    param($wordToComplete, $commandAst, $cursorPosition)")
+	fmt.Fprintf(w, "    $commands = %s\n", quote(spec.commandNames()))
+	fmt.Fprintf(w, "    $flags = %s\n", quote(spec.flagNames()))
+	fmt.Fprintln(w, "    $values = @{")
+	for _, name := range spec.valueNames() {
+		fmt.Fprintf(w, "        '-%s' = %s\n", name, quote(spec.Values[name]))
+	}
+	for _, name := range sortedKeys(spec.Args) {
+		fmt.Fprintf(w, "        '%s' = %s\n", name, quote(spec.Args[name]))
+	}
+	fmt.Fprintln(w, "    }")
+	fmt.Fprintf(w, "    $lists = %s\n", quote([]string{"-k", "-safe"}))
+	fmt.Fprintln(w, "    $words = @($commandAst.CommandElements | ForEach-Object { $_.ToString() })")
+	fmt.Fprintln(w, "    $index = $words.Count - 1")
+	fmt.Fprintln(w, "    if ($wordToComplete -ne '') { $index-- }")
+	fmt.Fprintln(w, "    $prev = if ($index -ge 1) { $words[$index] } else { '' }")
+	fmt.Fprintln(w, "    $prefix = ''")
+	fmt.Fprintln(w, "    $word = $wordToComplete")
+	fmt.Fprintln(w, "    if ($values.ContainsKey($prev)) {")
+	fmt.Fprintln(w, "        $candidates = $values[$prev]")
+	fmt.Fprintln(w, "        if ($lists -contains $prev -and $word.Contains(',')) {")
+	fmt.Fprintln(w, "            $prefix = $word.Substring(0, $word.LastIndexOf(',') + 1)")
+	fmt.Fprintln(w, "            $word = $word.Substring($prefix.Length)")
+	fmt.Fprintln(w, "        }")
+	fmt.Fprintln(w, "    } elseif ($word.StartsWith('-')) {")
+	fmt.Fprintln(w, "        $candidates = $flags")
+	fmt.Fprintln(w, "    } elseif ($index -eq 0) {")
+	fmt.Fprintln(w, "        $candidates = $commands")
+	fmt.Fprintln(w, "    } else {")
+	fmt.Fprintln(w, "        return")
+	fmt.Fprintln(w, "    }")
+	fmt.Fprintln(w, "    $candidates | Where-Object { $_ -like \"$word*\" } | ForEach-Object {")
+	fmt.Fprintln(w, "        [System.Management.Automation.CompletionResult]::new($prefix + $_, $_, 'ParameterValue', $_)")
+	fmt.Fprintln(w, "    }")
+	fmt.Fprintln(w, "}")
+}
+
+// flagUsage returns the first line of the usage of f, without backquotes of the name of its value.
+func flagUsage(f *flag.Flag) string {
+	_, usage := flag.UnquoteUsage(f)
+	line, _, _ := strings.Cut(usage, "\n")
+	return line
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Keys of the config file for flags of short names
+var configKeyAliases = map[string]string{
+	"kinds":  "k",
+	"length": "l",
+	"count":  "n",
+}
+
+// Flags written to the starter file of config init, in order
+var starterConfigFlags = []string{"k", "l", "n", "no-ambiguous", "exclude", "format", "group", "color", "policy-preset", "min-entropy", "history"}
+
+// configFilePath returns the path of the config file, ~/.config/gotpasswd/config.toml on Linux.
+func configFilePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gotpasswd", "config.toml"), nil
+}
+
+// LoadConfigFile sets flags by the config file, written in a subset of TOML of flag names:
+//
+//	kinds = ["alphabet", "number"]
+//	length = 20
+//	no-ambiguous = true
+//	min = ["upper=1", "number=1"]
+//
+// Arrays are set one by one for repeatable flags, or joined by commas. Flags in set, which are given by
+// sources of higher precedence, are left as is, and flags of the file are added to set. Missing files are ignored.
+func LoadConfigFile(flags *flag.FlagSet, path string, set map[string]bool) error {
+	entries, err := readTOMLFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	names := make(map[string]bool)
+	for _, entry := range entries {
+		fail := func(format string, args ...interface{}) error {
+			return errors.New(fmt.Sprintf("%s:%d: %s", path, entry.Line, fmt.Sprintf(format, args...)))
+		}
+		key := entry.Key
+		if name, ok := configKeyAliases[key]; ok {
+			key = name
+		}
+		f := flags.Lookup(key)
+		if f == nil || key == "no-config" {
+			return fail("unknown key: %s", entry.Key)
+		}
+		if set[key] {
+			continue
+		}
+		names[key] = true
+		values := entry.Values
+		if !entry.Array || !isRepeatableFlag(key) {
+			values = []string{strings.Join(values, ",")}
+		}
+		for _, value := range values {
+			if err := f.Value.Set(value); err != nil {
+				return fail("invalid value of %s: %s", entry.Key, err)
+			}
+		}
+	}
+	for name := range names {
+		set[name] = true
+	}
+	return nil
+}
+
+func isRepeatableFlag(name string) bool {
+	switch name {
+	case "min", "max", "var", "gen", "require-one-of":
+		return true
+	}
+	return false
+}
+
+// InitConfigFile writes a starter config file of commented defaults, not overwriting the existing one unless force.
+func InitConfigFile(path string, force bool) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	content := new(strings.Builder)
+	fmt.Fprintln(content, "# Defaults of gotpasswd, overridden by flags and ignored by -no-config.")
+	fmt.Fprintln(content, "# Keys are names of flags, also kinds, length and count for -k, -l and -n.")
+	for _, name := range starterConfigFlags {
+		f := flag.Lookup(name)
+		key := name
+		for alias, flagName := range configKeyAliases {
+			if flagName == name {
+				key = alias
+			}
+		}
+		fmt.Fprintf(content, "\n# %s\n# %s = %s\n", flagUsage(f), key, configValueOf(f))
+	}
+	mode := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if force {
+		mode = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	file, err := os.OpenFile(path, mode, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(content.String()); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// configValueOf formats the default of the flag as a value of the config file.
+func configValueOf(f *flag.Flag) string {
+	if isBoolFlag(f) {
+		return f.DefValue
+	}
+	if _, err := strconv.ParseFloat(f.DefValue, 64); err == nil {
+		return f.DefValue
+	}
+	if name := f.Name; name == "k" {
+		return `["` + strings.Join(strings.Split(f.DefValue, ","), `", "`) + `"]`
+	}
+	return strconv.Quote(f.DefValue)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestFlagSet() (*flag.FlagSet, *string, *string, *bool, *genSpecs) {
+	flags := flag.NewFlagSet("gotpasswd", flag.ContinueOnError)
+	kinds := flags.String("k", "alphabet", "")
+	length := flags.String("l", "8", "")
+	noAmbiguous := flags.Bool("no-ambiguous", false, "")
+	gens := &genSpecs{}
+	flags.Var(gens, "gen", "")
+	return flags, kinds, length, noAmbiguous, gens
+}
+
+func writeTestFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	flags, kinds, length, noAmbiguous, gens := newTestFlagSet()
+	path := writeTestFile(t, `# defaults
+kinds = ["upper", "number"] # joined by commas
+length = 20
+no-ambiguous = true
+gen = ["nist=1", 'wifi=2']
+`)
+	set := make(map[string]bool)
+	if err := LoadConfigFile(flags, path, set); err != nil {
+		t.Fatal(err)
+	}
+	if *kinds != "upper,number" || *length != "20" || !*noAmbiguous {
+		t.Errorf("flags are -k %s -l %s -no-ambiguous %v", *kinds, *length, *noAmbiguous)
+	}
+	if gens.String() != "nist=1,wifi=2" {
+		t.Errorf("-gen is %s", gens.String())
+	}
+	for _, name := range []string{"k", "l", "no-ambiguous", "gen"} {
+		if !set[name] {
+			t.Errorf("%s is not added to set", name)
+		}
+	}
+}
+
+func TestLoadConfigFilePrecedence(t *testing.T) {
+	flags, _, length, _, gens := newTestFlagSet()
+	// given by the command line
+	if err := flags.Parse([]string{"-gen", "wifi-router=1"}); err != nil {
+		t.Fatal(err)
+	}
+	set := map[string]bool{"gen": true}
+	path := writeTestFile(t, "gen = [\"nist=1\"]\nlength = 12\n")
+	if err := LoadConfigFile(flags, path, set); err != nil {
+		t.Fatal(err)
+	}
+	if gens.String() != "wifi-router=1" {
+		t.Errorf("-gen of the config file is added to the command line: %s", gens.String())
+	}
+	if *length != "12" {
+		t.Errorf("-l is %s", *length)
+	}
+}
+
+func TestLoadConfigFileErrors(t *testing.T) {
+	for content, message := range map[string]string{
+		"nosuch = 1\n":       "unknown key",
+		"[generate]\n":       "tables are not supported",
+		"length = abc\n":     "strings must be quoted",
+		"kinds = [\"upper\"": "array must be closed",
+		"no-config = true\n": "unknown key",
+	} {
+		flags, _, _, _, _ := newTestFlagSet()
+		flags.Bool("no-config", false, "")
+		err := LoadConfigFile(flags, writeTestFile(t, content), make(map[string]bool))
+		if err == nil || !strings.Contains(err.Error(), message) || !strings.Contains(err.Error(), ":1:") {
+			t.Errorf("%q: error is %v, expected %s", content, err, message)
+		}
+	}
+	flags, _, _, _, _ := newTestFlagSet()
+	if err := LoadConfigFile(flags, filepath.Join(t.TempDir(), "missing.toml"), make(map[string]bool)); err != nil {
+		t.Errorf("missing file is not ignored: %s", err)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Iterations of PBKDF2-HMAC-SHA256, as recommended by OWASP
+const deriveIterations = 600000
+
+// NewDerivedReader returns a deterministic byte stream derived from the master secret and the salt of the site.
+// Salts are stored in saltFile, a new random salt is stored when the site has none or rotate is true.
+// Only salts are stored, so passwords are reproducible only with the master secret.
+func NewDerivedReader(saltFile string, site string, secret []byte, rotate bool) (io.Reader, error) {
+	salts, err := loadSalts(saltFile)
+	if err != nil {
+		return nil, err
+	}
+	salt, ok := salts[site]
+	if !ok || rotate {
+		salt = make([]byte, 16)
+		if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+			return nil, err
+		}
+		salts[site] = salt
+		if err := saveSalts(saltFile, salts); err != nil {
+			return nil, err
+		}
+	}
+
+	key, err := pbkdf2.Key(sha256.New, string(secret), append(append([]byte{}, salt...), site...), deriveIterations, sha256.Size)
+	if err != nil {
+		return nil, err
+	}
+	return &hmacStream{
+		secret: key,
+	}, nil
+}
+
+func loadSalts(path string) (map[string][]byte, error) {
+	salts := make(map[string][]byte)
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return salts, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &salts); err != nil {
+		return nil, err
+	}
+	return salts, nil
+}
+
+func saveSalts(path string, salts map[string][]byte) error {
+	content, err := json.MarshalIndent(salts, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(content, '\n'))
+}
+
+// writeFileAtomic writes content to a temporary file of 0600 and renames it to path, not to lose the file on failures.
+func writeFileAtomic(path string, content []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if err := file.Chmod(0600); err != nil {
+		file.Close()
+		return err
+	}
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
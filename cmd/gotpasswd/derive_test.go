@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func deriveTestBytes(t *testing.T, saltFile string, site string, rotate bool) []byte {
+	t.Helper()
+	r, err := NewDerivedReader(saltFile, site, []byte("master"), rotate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, 32)
+	if _, err := io.ReadFull(r, b); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestDerivedReader(t *testing.T) {
+	saltFile := filepath.Join(t.TempDir(), "salts.json")
+	first := deriveTestBytes(t, saltFile, "example.com", false)
+	if again := deriveTestBytes(t, saltFile, "example.com", false); !bytes.Equal(first, again) {
+		t.Error("the same salt must reproduce the password")
+	}
+	if other := deriveTestBytes(t, saltFile, "example.org", false); bytes.Equal(first, other) {
+		t.Error("other sites must have other passwords")
+	}
+	rotated := deriveTestBytes(t, saltFile, "example.com", true)
+	if bytes.Equal(first, rotated) {
+		t.Error("the rotated salt must change the password")
+	}
+	if again := deriveTestBytes(t, saltFile, "example.com", false); !bytes.Equal(rotated, again) {
+		t.Error("the rotated salt must be stored")
+	}
+
+	salts, err := loadSalts(saltFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(salts) != 2 {
+		t.Errorf("salts = %v, want of 2 sites", salts)
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "salts.json")
+	if err := os.WriteFile(path, []byte("old content, longer than the new one\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFileAtomic(path, []byte("new\n")); err != nil {
+		t.Fatal(err)
+	}
+	if content, err := os.ReadFile(path); err != nil || string(content) != "new\n" {
+		t.Errorf("content = %q, %v", content, err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, %v, want 0600", info.Mode(), err)
+	}
+	// the temporary file has been renamed, not copied
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 1 {
+		t.Errorf("entries = %v, %v, want the file only", entries, err)
+	}
+
+	// failures are reported, such as of a missing directory
+	if err := writeFileAtomic(filepath.Join(dir, "missing", "salts.json"), []byte("new\n")); err == nil {
+		t.Error("writeFileAtomic must fail without the directory")
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Prefix of environment variables of flags, such as GOTPASSWD_LENGTH and GOTPASSWD_NO_AMBIGUOUS
+const envPrefix = "GOTPASSWD_"
+
+// envFlagName returns the name of the flag of the environment variable, or empty if it's not of the prefix.
+func envFlagName(name string) string {
+	if !strings.HasPrefix(name, envPrefix) || name == clipboardClearEnv {
+		return ""
+	}
+	key := strings.ReplaceAll(strings.ToLower(strings.TrimPrefix(name, envPrefix)), "_", "-")
+	if alias, ok := configKeyAliases[key]; ok {
+		return alias
+	}
+	return key
+}
+
+// LoadEnv sets flags by environment variables of GOTPASSWD_ and the names of flags in upper cases and underscores, ignoring ones of no flags,
+// also GOTPASSWD_KINDS, GOTPASSWD_LENGTH and GOTPASSWD_COUNT for -k, -l and -n.
+// Values of -min, -max and -gen are separated by spaces, others are same as flags.
+// Flags in set are left as is, and flags of environ are added to set, like LoadConfigFile.
+func LoadEnv(flags *flag.FlagSet, environ []string, set map[string]bool) error {
+	// sorted to report errors reproducibly
+	environ = append([]string{}, environ...)
+	sort.Strings(environ)
+	names := make(map[string]bool)
+	for _, entry := range environ {
+		name, value, _ := strings.Cut(entry, "=")
+		key := envFlagName(name)
+		if key == "" {
+			continue
+		}
+		// variables of other uses may share the prefix
+		f := flags.Lookup(key)
+		if f == nil || set[key] {
+			continue
+		}
+		names[key] = true
+		values := []string{value}
+		switch key {
+		case "min", "max", "gen":
+			values = strings.Fields(value)
+		}
+		for _, v := range values {
+			if err := f.Value.Set(v); err != nil {
+				return errors.New(fmt.Sprintf("Invalid value of %s: %s", name, err))
+			}
+		}
+	}
+	for name := range names {
+		set[name] = true
+	}
+	return nil
+}
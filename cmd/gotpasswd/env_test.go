@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadEnv(t *testing.T) {
+	flags, kinds, length, noAmbiguous, gens := newTestFlagSet()
+	environ := []string{
+		"HOME=/home/user",
+		"GOTPASSWD_KINDS=upper,number",
+		"GOTPASSWD_LENGTH=20",
+		"GOTPASSWD_NO_AMBIGUOUS=1",
+		"GOTPASSWD_GEN=nist=1 wifi=2",
+		// not of flags
+		"GOTPASSWD_FOO=1",
+		clipboardClearEnv + "=45:0123",
+	}
+	set := make(map[string]bool)
+	if err := LoadEnv(flags, environ, set); err != nil {
+		t.Fatal(err)
+	}
+	if *kinds != "upper,number" || *length != "20" || !*noAmbiguous || gens.String() != "nist=1,wifi=2" {
+		t.Errorf("flags are -k %s -l %s -no-ambiguous %v -gen %s", *kinds, *length, *noAmbiguous, gens.String())
+	}
+	if !set["gen"] || set["foo"] {
+		t.Errorf("set is %v", set)
+	}
+}
+
+func TestLoadEnvPrecedence(t *testing.T) {
+	flags, _, length, _, gens := newTestFlagSet()
+	if err := flags.Parse([]string{"-gen", "wifi-router=1"}); err != nil {
+		t.Fatal(err)
+	}
+	set := map[string]bool{"gen": true}
+	if err := LoadEnv(flags, []string{"GOTPASSWD_GEN=nist=1", "GOTPASSWD_LENGTH=12"}, set); err != nil {
+		t.Fatal(err)
+	}
+	if gens.String() != "wifi-router=1" || *length != "12" {
+		t.Errorf("flags are -gen %s -l %s", gens.String(), *length)
+	}
+
+	// the config file sets only flags not set by the environment
+	path := writeTestFile(t, "length = 16\nno-ambiguous = true\n")
+	if err := LoadConfigFile(flags, path, set); err != nil {
+		t.Fatal(err)
+	}
+	if *length != "12" {
+		t.Errorf("-l of the environment is overridden by the config file: %s", *length)
+	}
+}
+
+func TestLoadEnvInvalidValue(t *testing.T) {
+	flags, _, _, _, _ := newTestFlagSet()
+	err := LoadEnv(flags, []string{"GOTPASSWD_NO_AMBIGUOUS=maybe"}, make(map[string]bool))
+	if err == nil || !strings.Contains(err.Error(), "GOTPASSWD_NO_AMBIGUOUS") {
+		t.Errorf("error is %v", err)
+	}
+}
@@ -0,0 +1,401 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/kamichidu/go-gotpasswd"
+)
+
+// Estimator estimates how hard a password is to guess.
+type Estimator interface {
+	Estimate(passwd string) *Estimate
+}
+
+type Estimate struct {
+	// Log10 of the estimated number of guesses
+	GuessesLog10 float64
+	// Strength score in 0..4, compatible with zxcvbn
+	Score int
+	// Human readable crack time (online attack, no throttling, 10 guesses/sec)
+	OnlineCrackTime string
+	// Human readable crack time (offline attack, fast hash, 1e10 guesses/sec)
+	OfflineCrackTime string
+}
+
+func (self *Estimate) String() string {
+	return fmt.Sprintf("online: %s, offline: %s", self.OnlineCrackTime, self.OfflineCrackTime)
+}
+
+// The estimator used by the CLI, replace it to use another implementation.
+var estimator Estimator = &ZxcvbnEstimator{}
+
+// ZxcvbnEstimator is a small port of the zxcvbn guess estimation.
+// It finds the most guessable sequence of dictionary, l33t, spatial, repeat, sequence and bruteforce matches.
+type ZxcvbnEstimator struct{}
+
+const (
+	bruteforceCardinality           = 10
+	minGuessesBeforeGrowingSequence = 10000
+	minSubmatchGuessesSingleChar    = 10
+	minSubmatchGuessesMultiChar     = 50
+	maxEstimateLength               = 100
+	maxDictionaryWordLength         = 20
+	minSpatialLength                = 3
+	spatialKeyboard                 = "qwerty"
+)
+
+type estimateMatch struct {
+	i, j         int
+	guessesLog10 float64
+}
+
+func (self *ZxcvbnEstimator) Estimate(passwd string) *Estimate {
+	chars := []rune(passwd)
+	// Same as zxcvbn, long inputs are truncated to keep the matching cheap
+	if len(chars) > maxEstimateLength {
+		chars = chars[:maxEstimateLength]
+	}
+	guessesLog10 := self.guessesLog10(chars)
+	return &Estimate{
+		GuessesLog10:     guessesLog10,
+		Score:            guessesToScore(guessesLog10),
+		OnlineCrackTime:  displayTime(guessesLog10 - 1),
+		OfflineCrackTime: displayTime(guessesLog10 - 10),
+	}
+}
+
+func (self *ZxcvbnEstimator) guessesLog10(chars []rune) float64 {
+	n := len(chars)
+	if n == 0 {
+		return 0
+	}
+
+	matchesByEnd := make([][]estimateMatch, n)
+	for _, m := range self.matches(chars) {
+		matchesByEnd[m.j] = append(matchesByEnd[m.j], m)
+	}
+
+	// best[k][l]: log10 of the product of guesses of the best l matches covering chars[0:k+1]
+	best := make([]map[int]float64, n)
+	for k := 0; k < n; k++ {
+		best[k] = make(map[int]float64)
+		update := func(l int, v float64) {
+			if cur, ok := best[k][l]; !ok || v < cur {
+				best[k][l] = v
+			}
+		}
+		// bruteforce from any position
+		for i := 0; i <= k; i++ {
+			bf := bruteforceGuessesLog10(k-i+1, k-i+1 < n)
+			if i == 0 {
+				update(1, bf)
+			} else {
+				for l, v := range best[i-1] {
+					update(l+1, v+bf)
+				}
+			}
+		}
+		for _, m := range matchesByEnd[k] {
+			if m.i == 0 {
+				update(1, m.guessesLog10)
+			} else {
+				for l, v := range best[m.i-1] {
+					update(l+1, v+m.guessesLog10)
+				}
+			}
+		}
+	}
+
+	guessesLog10 := math.Inf(1)
+	for l, v := range best[n-1] {
+		lgamma, _ := math.Lgamma(float64(l + 1))
+		total := log10Add(lgamma/math.Ln10+v, float64(l-1)*math.Log10(minGuessesBeforeGrowingSequence))
+		if total < guessesLog10 {
+			guessesLog10 = total
+		}
+	}
+	return guessesLog10
+}
+
+func (self *ZxcvbnEstimator) matches(chars []rune) []estimateMatch {
+	matches := make([]estimateMatch, 0)
+	matches = append(matches, dictionaryMatches(chars)...)
+	matches = append(matches, spatialMatches(chars)...)
+	matches = append(matches, self.repeatMatches(chars)...)
+	matches = append(matches, sequenceMatches(chars)...)
+	return matches
+}
+
+func (self *ZxcvbnEstimator) repeatMatches(chars []rune) []estimateMatch {
+	n := len(chars)
+	matches := make([]estimateMatch, 0)
+	for i := 0; i < n; i++ {
+		end := i
+		for size := 1; i+2*size <= n; size++ {
+			base := chars[i : i+size]
+			count := 1
+			for i+(count+1)*size <= n && string(chars[i+count*size:i+(count+1)*size]) == string(base) {
+				count++
+			}
+			if count < 2 {
+				continue
+			}
+			baseGuessesLog10 := self.guessesLog10(base)
+			matches = append(matches, estimateMatch{
+				i:            i,
+				j:            i + count*size - 1,
+				guessesLog10: submatchGuessesLog10(baseGuessesLog10+math.Log10(float64(count)), count*size, count*size < n),
+			})
+			if i+count*size-1 > end {
+				end = i + count*size - 1
+			}
+		}
+		i = end
+	}
+	return matches
+}
+
+func sequenceMatches(chars []rune) []estimateMatch {
+	n := len(chars)
+	matches := make([]estimateMatch, 0)
+	for i := 0; i+2 < n; {
+		delta := chars[i+1] - chars[i]
+		j := i + 1
+		for j+1 < n && chars[j+1]-chars[j] == delta {
+			j++
+		}
+		if delta != 0 && delta >= -5 && delta <= 5 && j-i+1 >= 3 {
+			var base float64
+			switch first := chars[i]; {
+			case first == 'a' || first == 'A' || first == 'z' || first == 'Z' || first == '0' || first == '1' || first == '9':
+				base = 4
+			case first >= '0' && first <= '9':
+				base = 10
+			default:
+				base = 26
+			}
+			if delta < 0 {
+				base *= 2
+			}
+			matches = append(matches, estimateMatch{
+				i:            i,
+				j:            j,
+				guessesLog10: submatchGuessesLog10(math.Log10(base*float64(j-i+1)), j-i+1, j-i+1 < n),
+			})
+			i = j
+		} else {
+			i++
+		}
+	}
+	return matches
+}
+
+// dictionaryMatches finds known words, also spelled in upper cases and l33t characters.
+func dictionaryMatches(chars []rune) []estimateMatch {
+	n := len(chars)
+	matches := make([]estimateMatch, 0)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n && j-i < maxDictionaryWordLength; j++ {
+			token := chars[i : j+1]
+			lower := []rune(strings.ToLower(string(token)))
+			variationsLog10 := uppercaseVariationsLog10(token)
+			rank := gotpasswd.DictionaryRank(string(lower))
+			if rank == 0 {
+				// l33t characters are the letters they substitute, each doubles guesses
+				substituted := 0
+				for k, r := range lower {
+					if letters := gotpasswd.LeetLetters(r); letters != "" {
+						lower[k] = rune(letters[0])
+						substituted++
+					}
+				}
+				if substituted == 0 {
+					continue
+				}
+				if rank = gotpasswd.DictionaryRank(string(lower)); rank == 0 {
+					continue
+				}
+				variationsLog10 += float64(substituted) * math.Log10(2)
+			}
+			matches = append(matches, estimateMatch{
+				i:            i,
+				j:            j,
+				guessesLog10: submatchGuessesLog10(math.Log10(float64(rank))+variationsLog10, j-i+1, j-i+1 < n),
+			})
+		}
+	}
+	return matches
+}
+
+// uppercaseVariationsLog10 is log10 of the ways to capitalize the word as token, same as zxcvbn.
+func uppercaseVariationsLog10(token []rune) float64 {
+	uppers, lowers := 0, 0
+	for _, r := range token {
+		if unicode.IsUpper(r) {
+			uppers++
+		} else if unicode.IsLower(r) {
+			lowers++
+		}
+	}
+	if uppers == 0 {
+		return 0
+	}
+	// capitalized, all uppers and an upper at the end are common
+	if lowers == 0 || (uppers == 1 && (unicode.IsUpper(token[0]) || unicode.IsUpper(token[len(token)-1]))) {
+		return math.Log10(2)
+	}
+	variations := 0.0
+	for k := 1; k <= min(uppers, lowers); k++ {
+		variations += binomial(uppers+lowers, k)
+	}
+	return math.Log10(variations)
+}
+
+func binomial(n, k int) float64 {
+	v := 1.0
+	for i := 1; i <= k; i++ {
+		v = v * float64(n-k+i) / float64(i)
+	}
+	return v
+}
+
+// spatialMatches finds runs of adjacent keys on a qwerty keyboard, such as "zxcvb" and "1qaz".
+func spatialMatches(chars []rune) []estimateMatch {
+	n := len(chars)
+	starts := float64(gotpasswd.KeyCount(spatialKeyboard))
+	degree := float64(gotpasswd.KeyDirections)
+	matches := make([]estimateMatch, 0)
+	for i := 0; i < n; {
+		j := i
+		turns, shifted, last := 0, 0, -1
+		for j+1 < n {
+			direction := gotpasswd.KeyDirection(spatialKeyboard, chars[j], chars[j+1])
+			if direction < 0 {
+				break
+			}
+			if direction != last {
+				turns++
+				last = direction
+			}
+			j++
+		}
+		if j-i+1 < minSpatialLength {
+			i++
+			continue
+		}
+		for _, r := range chars[i : j+1] {
+			if gotpasswd.IsShiftedKey(spatialKeyboard, r) {
+				shifted++
+			}
+		}
+		// same as zxcvbn, sum of the ways to walk with up to the turns
+		length := j - i + 1
+		guesses := 0.0
+		for l := 2; l <= length; l++ {
+			for t := 1; t <= min(turns, l-1); t++ {
+				guesses += binomial(l-1, t-1) * starts * math.Pow(degree, float64(t))
+			}
+		}
+		guessesLog10 := math.Log10(guesses)
+		if shifted > 0 {
+			guessesLog10 += uppercaseVariationsLog10(shiftPattern(chars[i : j+1]))
+		}
+		matches = append(matches, estimateMatch{
+			i:            i,
+			j:            j,
+			guessesLog10: submatchGuessesLog10(guessesLog10, length, length < n),
+		})
+		i = j + 1
+	}
+	return matches
+}
+
+// shiftPattern maps keys typed with shift to 'A' and others to 'a', to count variations like cases.
+func shiftPattern(chars []rune) []rune {
+	pattern := make([]rune, len(chars))
+	for i, r := range chars {
+		pattern[i] = 'a'
+		if gotpasswd.IsShiftedKey(spatialKeyboard, r) {
+			pattern[i] = 'A'
+		}
+	}
+	return pattern
+}
+
+func bruteforceGuessesLog10(size int, submatch bool) float64 {
+	return submatchGuessesLog10(float64(size)*math.Log10(bruteforceCardinality), size, submatch)
+}
+
+func submatchGuessesLog10(guessesLog10 float64, size int, submatch bool) float64 {
+	if !submatch {
+		return guessesLog10
+	}
+	min := float64(minSubmatchGuessesMultiChar)
+	if size == 1 {
+		min = minSubmatchGuessesSingleChar
+	}
+	return math.Max(guessesLog10, math.Log10(min+1))
+}
+
+func log10Add(a, b float64) float64 {
+	if a < b {
+		a, b = b, a
+	}
+	return a + math.Log10(1+math.Pow(10, b-a))
+}
+
+func guessesToScore(guessesLog10 float64) int {
+	switch {
+	case guessesLog10 < math.Log10(1e3+5):
+		return 0
+	case guessesLog10 < math.Log10(1e6+5):
+		return 1
+	case guessesLog10 < math.Log10(1e8+5):
+		return 2
+	case guessesLog10 < math.Log10(1e10+5):
+		return 3
+	default:
+		return 4
+	}
+}
+
+func displayTime(secondsLog10 float64) string {
+	const (
+		minute  = 60
+		hour    = minute * 60
+		day     = hour * 24
+		month   = day * 31
+		year    = month * 12
+		century = year * 100
+	)
+	if secondsLog10 >= math.Log10(century) {
+		return "centuries"
+	}
+	seconds := math.Pow(10, secondsLog10)
+	unit := func(v float64, name string) string {
+		n := int64(math.Round(v))
+		if n == 1 {
+			return fmt.Sprintf("%d %s", n, name)
+		}
+		return fmt.Sprintf("%d %ss", n, name)
+	}
+	switch {
+	case seconds < 1:
+		return "less than a second"
+	case seconds < minute:
+		return unit(seconds, "second")
+	case seconds < hour:
+		return unit(seconds/minute, "minute")
+	case seconds < day:
+		return unit(seconds/hour, "hour")
+	case seconds < month:
+		return unit(seconds/day, "day")
+	case seconds < year:
+		return unit(seconds/month, "month")
+	default:
+		return unit(seconds/year, "year")
+	}
+}
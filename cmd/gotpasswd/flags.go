@@ -0,0 +1,155 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kamichidu/go-gotpasswd"
+)
+
+// repeatableValue is a flag.Value accepting repeated values, which are set one by one to reproduce it.
+type repeatableValue interface {
+	flag.Value
+	Values() []string
+}
+
+// stringsFlag is a flag.Value accepting repeated values.
+type stringsFlag []string
+
+func (self *stringsFlag) String() string {
+	return strings.Join(self.Values(), ",")
+}
+
+func (self *stringsFlag) Values() []string {
+	return *self
+}
+
+func (self *stringsFlag) Set(s string) error {
+	*self = append(*self, s)
+	return nil
+}
+
+type genSpec struct {
+	profile string
+	count   int
+}
+
+// genSpecs is a flag.Value accepting repeated "profile=count".
+type genSpecs []genSpec
+
+func (self *genSpecs) String() string {
+	return strings.Join(self.Values(), ",")
+}
+
+func (self *genSpecs) Values() []string {
+	specs := make([]string, len(*self))
+	for i, spec := range *self {
+		specs[i] = fmt.Sprintf("%s=%d", spec.profile, spec.count)
+	}
+	return specs
+}
+
+func (self *genSpecs) Set(s string) error {
+	pair := strings.SplitN(s, "=", 2)
+	if len(pair) != 2 {
+		return errors.New(fmt.Sprintf("Invalid spec, must be profile=count: %s", s))
+	}
+	count, err := strconv.Atoi(pair[1])
+	if err != nil || count <= 0 {
+		return errors.New(fmt.Sprintf("Count must be positive: %s", s))
+	}
+	*self = append(*self, genSpec{profile: pair[0], count: count})
+	return nil
+}
+
+// kindCounts is a flag.Value accepting repeated "kind=count", kinds joined by commas take the count each.
+type kindCounts map[gotpasswd.CharacterKind]int
+
+func (self kindCounts) String() string {
+	return strings.Join(self.Values(), ",")
+}
+
+func (self kindCounts) Values() []string {
+	specs := make([]string, 0, len(self))
+	for kind, count := range self {
+		specs = append(specs, fmt.Sprintf("%s=%d", kind, count))
+	}
+	sort.Strings(specs)
+	return specs
+}
+
+func (self kindCounts) Set(s string) error {
+	pair := strings.SplitN(s, "=", 2)
+	if len(pair) != 2 {
+		return errors.New(fmt.Sprintf("Invalid spec, must be kind=count: %s", s))
+	}
+	kinds, err := (&gotpasswd.Config{}).ParseKinds(pair[0])
+	if err != nil {
+		return err
+	}
+	count, err := strconv.Atoi(pair[1])
+	if err != nil || count < 0 {
+		return errors.New(fmt.Sprintf("Count must not be negative: %s", s))
+	}
+	for _, kind := range kinds {
+		self[kind] = count
+	}
+	return nil
+}
+
+// lengthRange is a flag.Value accepting a length "N", or a range of lengths "MIN-MAX".
+type lengthRange struct {
+	min, max int
+}
+
+func (self *lengthRange) String() string {
+	if self.min == self.max {
+		return strconv.Itoa(self.min)
+	}
+	return fmt.Sprintf("%d-%d", self.min, self.max)
+}
+
+func (self *lengthRange) Set(s string) error {
+	bounds := strings.SplitN(s, "-", 2)
+	min, err := strconv.Atoi(bounds[0])
+	if err != nil {
+		return errors.New(fmt.Sprintf("Invalid length: %s", s))
+	}
+	max := min
+	if len(bounds) == 2 {
+		if max, err = strconv.Atoi(bounds[1]); err != nil || max < min {
+			return errors.New(fmt.Sprintf("Invalid range of lengths, must be min-max: %s", s))
+		}
+	}
+	self.min, self.max = min, max
+	return nil
+}
+
+// templateVars is a flag.Value accepting repeated "name=value".
+type templateVars map[string]string
+
+func (self templateVars) String() string {
+	return strings.Join(self.Values(), ",")
+}
+
+func (self templateVars) Values() []string {
+	specs := make([]string, 0, len(self))
+	for name, value := range self {
+		specs = append(specs, name+"="+value)
+	}
+	sort.Strings(specs)
+	return specs
+}
+
+func (self templateVars) Set(s string) error {
+	pair := strings.SplitN(s, "=", 2)
+	if len(pair) != 2 || pair[0] == "" {
+		return errors.New(fmt.Sprintf("Invalid var, must be name=value: %s", s))
+	}
+	self[pair[0]] = pair[1]
+	return nil
+}
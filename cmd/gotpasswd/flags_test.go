@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/kamichidu/go-gotpasswd"
+)
+
+func TestKindCountsSet(t *testing.T) {
+	counts := kindCounts{}
+	for _, s := range []string{"alphabet,number=2", "symbol=1", "number=3"} {
+		if err := counts.Set(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(counts) != 3 || counts[gotpasswd.ALPHABET] != 2 || counts[gotpasswd.NUMBER] != 3 || counts[gotpasswd.SYMBOL] != 1 {
+		t.Errorf("counts = %v", counts)
+	}
+	for _, s := range []string{"alphabet", "alphabet=-1", "unknown=1", "alphabet,number=x"} {
+		if err := (kindCounts{}).Set(s); err == nil {
+			t.Errorf("Set(%q) must fail", s)
+		}
+	}
+}
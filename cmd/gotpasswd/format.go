@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kamichidu/go-gotpasswd"
+)
+
+// OutputWriter writes generated passwords in a structured format instead of lines.
+type OutputWriter interface {
+	Write(index int, label string, passwd gotpasswd.Password) error
+	Close() error
+}
+
+// Structured formats of -format, besides text
+var outputFormats = map[string]func(w io.Writer) OutputWriter{
+	"json":  NewJSONWriter,
+	"jsonl": NewJSONLinesWriter,
+}
+
+// Estimator of crack times of records, set by -crack-estimate, nil to omit them
+var recordEstimator Estimator
+
+// Include fuzzy hashes of passwords in records, set by -ssdeep
+var recordFuzzyHash bool
+
+type passwordRecord struct {
+	Index         int                  `json:"index"`
+	Label         string               `json:"label,omitempty"`
+	Password      string               `json:"password"`
+	Length        int                  `json:"length"`
+	Kinds         []string             `json:"kinds"`
+	EntropyBits   float64              `json:"entropy_bits"`
+	CrackEstimate *crackEstimateRecord `json:"crack_estimate,omitempty"`
+	FuzzyHash     string               `json:"fuzzy_hash,omitempty"`
+}
+
+type crackEstimateRecord struct {
+	Score        int     `json:"score"`
+	GuessesLog10 float64 `json:"guesses_log10"`
+	Online       string  `json:"online"`
+	Offline      string  `json:"offline"`
+}
+
+func newPasswordRecord(index int, label string, passwd gotpasswd.Password) *passwordRecord {
+	kinds := make([]string, len(passwd.Kinds))
+	for i, kind := range passwd.Kinds {
+		kinds[i] = kind.String()
+	}
+	record := &passwordRecord{
+		Index:       index,
+		Label:       label,
+		Password:    passwd.Value,
+		Length:      utf8.RuneCountInString(passwd.Value),
+		Kinds:       kinds,
+		EntropyBits: passwd.Entropy,
+	}
+	if recordFuzzyHash {
+		record.FuzzyHash = FuzzyHash(passwd.Value)
+	}
+	if recordEstimator != nil {
+		estimate := recordEstimator.Estimate(passwd.Value)
+		record.CrackEstimate = &crackEstimateRecord{
+			Score:        estimate.Score,
+			GuessesLog10: estimate.GuessesLog10,
+			Online:       estimate.OnlineCrackTime,
+			Offline:      estimate.OfflineCrackTime,
+		}
+	}
+	return record
+}
+
+// JSONWriter writes a JSON array of passwords, elements are written as they are generated.
+type JSONWriter struct {
+	w     io.Writer
+	count int
+}
+
+func NewJSONWriter(w io.Writer) OutputWriter {
+	return &JSONWriter{w: w}
+}
+
+func (self *JSONWriter) Write(index int, label string, passwd gotpasswd.Password) error {
+	b, err := json.Marshal(newPasswordRecord(index, label, passwd))
+	if err != nil {
+		return err
+	}
+	prefix := ",\n  "
+	if self.count == 0 {
+		prefix = "[\n  "
+	}
+	self.count++
+	_, err = io.WriteString(self.w, prefix+string(b))
+	return err
+}
+
+// Close terminates the array, which is empty if no passwords were written.
+func (self *JSONWriter) Close() error {
+	suffix := "\n]\n"
+	if self.count == 0 {
+		suffix = "[]\n"
+	}
+	_, err := io.WriteString(self.w, suffix)
+	return err
+}
+
+// JSONLinesWriter writes a JSON object per line, so that outputs are streamed without buffering.
+type JSONLinesWriter struct {
+	encoder *json.Encoder
+}
+
+func NewJSONLinesWriter(w io.Writer) OutputWriter {
+	return &JSONLinesWriter{encoder: json.NewEncoder(w)}
+}
+
+func (self *JSONLinesWriter) Write(index int, label string, passwd gotpasswd.Password) error {
+	return self.encoder.Encode(newPasswordRecord(index, label, passwd))
+}
+
+func (self *JSONLinesWriter) Close() error {
+	return nil
+}
+
+// Columns of -format csv, formatted from passwordRecord
+var csvColumns = map[string]func(record *passwordRecord) string{
+	"index":    func(record *passwordRecord) string { return strconv.Itoa(record.Index) },
+	"label":    func(record *passwordRecord) string { return record.Label },
+	"password": func(record *passwordRecord) string { return record.Password },
+	"length":   func(record *passwordRecord) string { return strconv.Itoa(record.Length) },
+	"kinds":    func(record *passwordRecord) string { return strings.Join(record.Kinds, ",") },
+	"entropy":  func(record *passwordRecord) string { return strconv.FormatFloat(record.EntropyBits, 'f', 1, 64) },
+	"crack_estimate": func(record *passwordRecord) string {
+		if record.CrackEstimate == nil {
+			return estimator.Estimate(record.Password).String()
+		}
+		return fmt.Sprintf("online: %s, offline: %s", record.CrackEstimate.Online, record.CrackEstimate.Offline)
+	},
+	"fuzzy_hash": func(record *passwordRecord) string { return FuzzyHash(record.Password) },
+}
+
+// CSVWriter writes a header and a row per password of the columns, quoted as RFC 4180.
+type CSVWriter struct {
+	w       *csv.Writer
+	columns []string
+}
+
+func NewCSVWriter(w io.Writer, columns []string) (*CSVWriter, error) {
+	for _, column := range columns {
+		if _, ok := csvColumns[column]; !ok {
+			return nil, errors.New(fmt.Sprintf("Unknown column: %s", column))
+		}
+	}
+	self := &CSVWriter{w: csv.NewWriter(w), columns: columns}
+	if err := self.w.Write(columns); err != nil {
+		return nil, err
+	}
+	return self, nil
+}
+
+// Write flushes each row, so that rows are streamed like jsonl.
+func (self *CSVWriter) Write(index int, label string, passwd gotpasswd.Password) error {
+	record := newPasswordRecord(index, label, passwd)
+	row := make([]string, len(self.columns))
+	for i, column := range self.columns {
+		row[i] = csvColumns[column](record)
+	}
+	self.w.Write(row)
+	self.w.Flush()
+	return self.w.Error()
+}
+
+func (self *CSVWriter) Close() error {
+	self.w.Flush()
+	return self.w.Error()
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kamichidu/go-gotpasswd"
+)
+
+func TestCrackEstimateRecord(t *testing.T) {
+	recordEstimator = estimator
+	defer func() { recordEstimator = nil }()
+
+	generator := gotpasswd.NewGenerator(nil)
+	estimate := func(config *gotpasswd.Config) *crackEstimateRecord {
+		passwd, err := generator.GeneratePassword(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := json.Marshal(newPasswordRecord(1, "", passwd))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var record passwordRecord
+		if err := json.Unmarshal(b, &record); err != nil {
+			t.Fatal(err)
+		}
+		if record.CrackEstimate == nil || record.CrackEstimate.Online == "" || record.CrackEstimate.Offline == "" {
+			t.Fatalf("crack_estimate is not populated: %s", b)
+		}
+		return record.CrackEstimate
+	}
+	weak := estimate(&gotpasswd.Config{Kinds: []gotpasswd.CharacterKind{gotpasswd.NUMBER}, Length: 4})
+	strong := estimate(&gotpasswd.Config{Kinds: []gotpasswd.CharacterKind{gotpasswd.ALPHABET, gotpasswd.NUMBER, gotpasswd.SYMBOL}, Length: 24})
+	if weak.Score > 1 || weak.Offline != "less than a second" {
+		t.Errorf("weak password is estimated as score %d, offline %s", weak.Score, weak.Offline)
+	}
+	if strong.Score != 4 || strong.GuessesLog10 <= weak.GuessesLog10 {
+		t.Errorf("strong password is estimated as score %d, 10^%.1f guesses", strong.Score, strong.GuessesLog10)
+	}
+}
+
+func TestCrackEstimateOmitted(t *testing.T) {
+	b, err := json.Marshal(newPasswordRecord(1, "", gotpasswd.Password{Value: "secret"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]interface{}
+	json.Unmarshal(b, &fields)
+	if _, ok := fields["crack_estimate"]; ok {
+		t.Errorf("crack_estimate is included without -crack-estimate: %s", b)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+)
+
+// Parameters of spamsum, the context triggered piecewise hash used by ssdeep
+const (
+	fuzzyRollingWindow = 7
+	fuzzyMinBlockSize  = 3
+	fuzzyHashPrime     = 0x01000193
+	fuzzyHashInit      = 0x28021967
+	fuzzyHashLength    = 64
+	fuzzyBase64        = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+)
+
+type rollingHash struct {
+	h1, h2, h3 uint32
+	n          uint32
+	window     [fuzzyRollingWindow]uint32
+}
+
+func (self *rollingHash) roll(c byte) uint32 {
+	self.h2 -= self.h1
+	self.h2 += fuzzyRollingWindow * uint32(c)
+	self.h1 += uint32(c)
+	self.h1 -= self.window[self.n%fuzzyRollingWindow]
+	self.window[self.n%fuzzyRollingWindow] = uint32(c)
+	self.n++
+	self.h3 <<= 5
+	self.h3 ^= uint32(c)
+	return self.h1 + self.h2 + self.h3
+}
+
+// FuzzyHash computes the ssdeep style fuzzy hash "blocksize:hash1:hash2" of s.
+// Similar inputs have similar hashes, so it can be used to detect near duplicated passwords.
+func FuzzyHash(s string) string {
+	data := []byte(s)
+	blockSize := uint32(fuzzyMinBlockSize)
+	for blockSize*fuzzyHashLength < uint32(len(data)) {
+		blockSize *= 2
+	}
+
+	for {
+		var rolling rollingHash
+		h1, h2 := uint32(fuzzyHashInit), uint32(fuzzyHashInit)
+		hash1 := make([]byte, 0, fuzzyHashLength)
+		hash2 := make([]byte, 0, fuzzyHashLength/2)
+		for _, c := range data {
+			h1 = (h1 * fuzzyHashPrime) ^ uint32(c)
+			h2 = (h2 * fuzzyHashPrime) ^ uint32(c)
+			sum := rolling.roll(c)
+			if sum%blockSize == blockSize-1 && len(hash1) < fuzzyHashLength-1 {
+				hash1 = append(hash1, fuzzyBase64[h1%64])
+				h1 = fuzzyHashInit
+			}
+			if sum%(blockSize*2) == blockSize*2-1 && len(hash2) < fuzzyHashLength/2-1 {
+				hash2 = append(hash2, fuzzyBase64[h2%64])
+				h2 = fuzzyHashInit
+			}
+		}
+		if h1 != fuzzyHashInit {
+			hash1 = append(hash1, fuzzyBase64[h1%64])
+		}
+		if h2 != fuzzyHashInit {
+			hash2 = append(hash2, fuzzyBase64[h2%64])
+		}
+
+		if blockSize > fuzzyMinBlockSize && len(hash1) < fuzzyHashLength/2 {
+			blockSize /= 2
+			continue
+		}
+		return fmt.Sprintf("%d:%s:%s", blockSize, hash1, hash2)
+	}
+}
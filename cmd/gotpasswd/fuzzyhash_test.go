@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestFuzzyHash(t *testing.T) {
+	if hash := FuzzyHash(""); hash != "3::" {
+		t.Errorf("FuzzyHash of empty = %q, want 3::", hash)
+	}
+	format := regexp.MustCompile(`^\d+:[A-Za-z0-9+/]*:[A-Za-z0-9+/]*$`)
+	passwd := "correct horse battery staple " + strings.Repeat("Tr0ub4dor&3", 8)
+	hash := FuzzyHash(passwd)
+	if !format.MatchString(hash) {
+		t.Errorf("%q is not blocksize:hash1:hash2", hash)
+	}
+	if other := FuzzyHash(passwd); other != hash {
+		t.Errorf("hashes of identical inputs differ: %q, %q", hash, other)
+	}
+	different := strings.Repeat("0123456789", 10)
+	if other := FuzzyHash(different); other == hash {
+		t.Errorf("hashes of very different inputs are same: %q", hash)
+	}
+	// long inputs double the block size
+	if long := FuzzyHash(strings.Repeat(passwd, 20)); strings.HasPrefix(long, "3:") {
+		t.Errorf("block size of %q is not doubled", long)
+	}
+}
+
+func TestFuzzyHashOutput(t *testing.T) {
+	metaPath := filepath.Join(t.TempDir(), "meta.jsonl")
+	if _, _, status := runMain(t, nil, "-ssdeep", "-meta-out", metaPath); status != 128 {
+		t.Errorf("status = %d, want 128 of -ssdeep without -format", status)
+	}
+	stdout, stderr, status := runMain(t, nil, "-ssdeep", "-format", "jsonl", "-meta-out", metaPath)
+	if status != 0 {
+		t.Fatalf("status = %d, stderr = %q", status, stderr)
+	}
+	var record passwordRecord
+	if err := json.Unmarshal([]byte(stdout), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.FuzzyHash != FuzzyHash(record.Password) {
+		t.Errorf("fuzzy_hash = %q, want of %q", record.FuzzyHash, record.Password)
+	}
+	meta, err := os.ReadFile(metaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(meta), "fuzzy_hash") {
+		t.Errorf("%s must not have fuzzy hashes", meta)
+	}
+}
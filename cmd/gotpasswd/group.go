@@ -0,0 +1,15 @@
+package main
+
+import "strings"
+
+// GroupChars joins groups of size characters of s by sep, such as "ab3X-9kQ2" for transcription.
+func GroupChars(s string, size int, sep string) string {
+	chars := []rune(s)
+	groups := make([]string, 0, len(chars)/size+1)
+	for len(chars) > size {
+		groups = append(groups, string(chars[:size]))
+		chars = chars[size:]
+	}
+	groups = append(groups, string(chars))
+	return strings.Join(groups, sep)
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+// HashPassword hashes the password by PBKDF2-HMAC-SHA256 of a random salt,
+// in the PHC string format such as $pbkdf2-sha256$i=600000,l=32$salt$hash.
+func HashPassword(passwd string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return "", err
+	}
+	key, err := pbkdf2.Key(sha256.New, passwd, salt, deriveIterations, sha256.Size)
+	if err != nil {
+		return "", err
+	}
+	b64 := base64.RawStdEncoding
+	return fmt.Sprintf("$pbkdf2-sha256$i=%d,l=%d$%s$%s", deriveIterations, len(key), b64.EncodeToString(salt), b64.EncodeToString(key)), nil
+}
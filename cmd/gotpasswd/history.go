@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// History is a file of PBKDF2-HMAC-SHA256 hashes of passwords generated before, with a random salt of the file,
+// so that passwords are not reissued while the file reveals none of them even to guesses.
+// It rejects passwords in it as a gotpasswd.Validator.
+type History struct {
+	path       string
+	salt       []byte
+	iterations int
+	hashes     map[string]bool
+	// hashes in the order of additions
+	order []string
+	// the password validated last and its hash, not to derive it again on Add
+	lastPasswd, lastHash string
+}
+
+// Key derivation of new histories, recorded in the file with its parameters
+const (
+	historyKDF        = "pbkdf2-sha256"
+	historyIterations = deriveIterations
+)
+
+type historyFile struct {
+	KDF        string   `json:"kdf"`
+	Iterations int      `json:"iterations"`
+	Salt       []byte   `json:"salt"`
+	Hashes     []string `json:"hashes"`
+}
+
+// LoadHistory reads the history, which is empty with a new salt if the file doesn't exist.
+func LoadHistory(path string) (*History, error) {
+	self := &History{path: path, iterations: historyIterations, hashes: make(map[string]bool)}
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		self.salt = make([]byte, 16)
+		if _, err := io.ReadFull(rand.Reader, self.salt); err != nil {
+			return nil, err
+		}
+		return self, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var file historyFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, errors.New(fmt.Sprintf("%s: %s", path, err))
+	}
+	if file.KDF != historyKDF || file.Iterations <= 0 {
+		return nil, errors.New(fmt.Sprintf("%s: unsupported key derivation %q of %d iterations", path, file.KDF, file.Iterations))
+	} else if len(file.Salt) == 0 {
+		return nil, errors.New(fmt.Sprintf("%s: no salt", path))
+	}
+	self.salt = file.Salt
+	self.iterations = file.Iterations
+	for _, hash := range file.Hashes {
+		if !self.hashes[hash] {
+			self.hashes[hash] = true
+			self.order = append(self.order, hash)
+		}
+	}
+	return self, nil
+}
+
+func (self *History) hash(passwd string) (string, error) {
+	if self.lastHash != "" && passwd == self.lastPasswd {
+		return self.lastHash, nil
+	}
+	key, err := pbkdf2.Key(sha256.New, passwd, self.salt, self.iterations, sha256.Size)
+	if err != nil {
+		return "", err
+	}
+	self.lastPasswd, self.lastHash = passwd, hex.EncodeToString(key)
+	return self.lastHash, nil
+}
+
+// Validate rejects passwords in the history, including ones added in this run.
+func (self *History) Validate(ctx context.Context, passwd string) (bool, error) {
+	hash, err := self.hash(passwd)
+	if err != nil {
+		return false, err
+	}
+	return !self.hashes[hash], nil
+}
+
+// Add records the password, written by Save.
+func (self *History) Add(passwd string) error {
+	hash, err := self.hash(passwd)
+	if err != nil {
+		return err
+	}
+	if !self.hashes[hash] {
+		self.hashes[hash] = true
+		self.order = append(self.order, hash)
+	}
+	return nil
+}
+
+// Save writes the history with added passwords, creating its directory if missing.
+func (self *History) Save() error {
+	content, err := json.MarshalIndent(&historyFile{KDF: historyKDF, Iterations: self.iterations, Salt: self.salt, Hashes: self.order}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(self.path), 0700); err != nil {
+		return err
+	}
+	return writeFileAtomic(self.path, append(content, '\n'))
+}
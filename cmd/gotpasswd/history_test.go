@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gotpasswd", "history.json")
+	history, err := LoadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := history.Add("secret"); err != nil {
+		t.Fatal(err)
+	}
+	if err := history.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "secret") {
+		t.Errorf("%s reveals the password", content)
+	}
+	var file historyFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		t.Fatal(err)
+	}
+	if file.KDF != "pbkdf2-sha256" || file.Iterations != 600000 || len(file.Salt) != 16 || len(file.Hashes) != 1 {
+		t.Errorf("%s must record the parameters and the hash", content)
+	}
+
+	history, err = LoadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for passwd, want := range map[string]bool{"secret": false, "other": true} {
+		if ok, err := history.Validate(context.Background(), passwd); err != nil || ok != want {
+			t.Errorf("Validate(%q) = %v, %v, want %v", passwd, ok, err, want)
+		}
+	}
+}
+
+func TestLoadHistoryErrors(t *testing.T) {
+	for _, content := range []string{
+		`{"salt": "c2FsdA==", "hashes": []}`,
+		`{"kdf": "pbkdf2-sha256", "iterations": 0, "salt": "c2FsdA==", "hashes": []}`,
+		`{"kdf": "pbkdf2-sha256", "iterations": 600000, "hashes": []}`,
+	} {
+		if _, err := LoadHistory(writeTestFile(t, content)); err == nil {
+			t.Errorf("LoadHistory(%s) must fail", content)
+		}
+	}
+}
@@ -1,150 +1,374 @@
 package main
 
 import (
-	"crypto/rand"
-	"errors"
+	"bufio"
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
-	"math/big"
+	"io"
+	"math"
 	"os"
+	"os/signal"
+	"slices"
+	"strconv"
 	"strings"
-	"unicode"
+	"time"
+
+	"github.com/kamichidu/go-gotpasswd"
 )
 
 var (
-	kinds  = flag.String("k", "alphabet,number,symbol,underscore,space", "Character kinds")
-	length = flag.Int("l", 8, "Length of password")
+	kinds  = flag.String("k", "alphabet,number,symbol,underscore,space", "Character kinds (alphabet is an alias of upper,lower), also unicode categories and scripts such as Lu, Nd, greek and kana")
+	length = &lengthRange{min: 8, max: 8}
 	num    = flag.Int("n", 1, "Number of passwords")
-	debug  = flag.Bool("debug", false, "DO NOT USE THIS")
-)
-
-type CharacterKind int
 
-const (
-	ALPHABET CharacterKind = iota
-	NUMBER
-	SYMBOL
-	UNDERSCORE
-	SPACE
+	crackEstimate      = flag.Bool("crack-estimate", false, "Print estimated crack times for each password, also as crack_estimate of -format json, jsonl and csv")
+	fontSafe           = flag.String("font-safe", "", "Exclude characters confusable in the font family (monospace, serif)")
+	noRepeatedBigrams  = flag.Bool("no-repeated-bigrams", false, "Do not repeat any two-character sequence in a password")
+	noRepeat           = flag.Bool("no-repeat", false, "Use each character at most once in a password")
+	noAdjacentRepeat   = flag.Bool("no-adjacent-repeat", false, "Do not repeat a character twice in a row")
+	layout             = flag.String("layout", "", "Restrict characters to keyboard layout keys, reduces entropy (qwerty-homerow, dvorak-homerow)")
+	grammar            = flag.String("grammar", "", "Generate passwords structured by the grammar, e.g. '[A-Z]{2}[0-9]{4}[!@#]' (overrides -k and -l)")
+	metaOut            = flag.String("meta-out", "", "Write JSON metadata of each password to the file, one per line")
+	timeBucket         = flag.Duration("time-bucket", 0, "Derive deterministic passwords per time window from -secret (not a TOTP, test use only)")
+	secret             = flag.String("secret", "", "Secret key for -time-bucket and -derive-salt")
+	timeout            = flag.Duration("timeout", 0, "Give up generation after the duration")
+	minTransitions     = flag.Int("min-transitions", 0, "Minimum number of character kind changes between adjacent characters")
+	positions          = flag.String("positions", "", "Allowed characters for each position, e.g. '0:ABCDEF,1:0123456789'")
+	words              = flag.Int("words", 0, "Generate passphrases of the number of words instead (overrides -k and -l)")
+	pinSuffix          = flag.Int("pin-suffix", 0, "Append the number of digits to passphrases")
+	noProfanity        = flag.Bool("no-profanity", false, "Avoid flagged words in passwords, best-effort")
+	profanityList      = flag.String("profanity-list", "", "File of additional flagged words for -no-profanity, one per line")
+	seed               = flag.String("seed", "", "Generate reproducible passwords from the seed, NOT secret")
+	share              = flag.Bool("share", false, "Print a command line reproducing the output of -seed")
+	charsetWeighted    = flag.String("charset-weighted", "", "Draw characters from weighted charsets, e.g. 'vowels=aeiou:3,consonants=bcdfg:7' (overrides -k)")
+	describe           = flag.Bool("describe", false, "Print available kinds and presets as JSON")
+	allowedLengths     = flag.String("allowed-lengths", "", "Comma separated lengths chosen randomly for each password (overrides -l)")
+	dateSuffix         = flag.String("date-suffix", "", "Append the current date in the Go time layout, e.g. 2006-01, it adds no secrecy")
+	targetEncoding     = flag.String("target-encoding", "", "Use only characters encodable in the encoding (latin1, shiftjis)")
+	againstRegexFile   = flag.String("against-regex-file", "", "File of regular expressions, one per line, which passwords must match all of")
+	minCaseChanges     = flag.Int("min-case-changes", 0, "Minimum number of changes between lower and upper case letters")
+	color              = flag.Bool("color", false, "Color characters by their kinds on a terminal, disabled by NO_COLOR")
+	ssdeep             = flag.Bool("ssdeep", false, "Include ssdeep style fuzzy hashes as fuzzy_hash of -format json, jsonl and csv, not in -meta-out as they reveal passwords")
+	noShift            = flag.Bool("no-shift", false, "Use only characters typeable without the shift key on a US keyboard, reduces entropy")
+	solver             = flag.Bool("solver", false, "Construct passwords satisfying constraints of kinds, -min counts, -positions and -start-with/-end-with instead of regenerating")
+	badge              = flag.String("badge", "", "Write an SVG badge of the entropy and strength to the file")
+	sqlSafe            = flag.String("sql-safe", "", "Avoid characters needing escapes in SQL string literals of the dialect (ansi, postgres, sqlite, mssql, mysql)")
+	sqlLiteral         = flag.Bool("sql-literal", false, "Print escaped SQL string literals of -sql-safe dialect, instead of avoiding characters (use ansi for mysql in NO_BACKSLASH_ESCAPES mode)")
+	deriveSalt         = flag.String("derive-salt", "", "Derive reproducible passwords for -site from -secret, storing per site salts in the file")
+	site               = flag.String("site", "", "Site name for -derive-salt")
+	rotateSalt         = flag.Bool("rotate-salt", false, "Rotate the salt of -site, to derive a new password")
+	maxDistinctSymbols = flag.Int("max-distinct-symbols", 0, "Max number of distinct symbol characters in a password")
+	pdf                = flag.String("pdf", "", "Write passwords to the file as printable PDF cards, one per page")
+	noLeetWords        = flag.Bool("no-leet-words", false, "Reject passwords which are dictionary words disguised by l33t substitutions")
+	minShannon         = flag.Float64("min-shannon", 0, "Min empirical Shannon entropy in bits per character of the characters drawn, unlike the entropy of the pool")
+	manifest           = flag.String("manifest", "", "Generate a password for each service in the YAML manifest into secrets/<service>")
+	kindWindow         = flag.String("kind-window", "", "Allow each kind at most MAX times in any SIZE consecutive characters, as `SIZE:MAX`")
+	validatorCmd       = flag.String("validator-cmd", "", "Regenerate until the `command` exits with zero, given the password on stdin, or replacing {} in it, which other users can read by ps")
+	randSource         = flag.String("rand-source", "", "Read randomness from the file or device instead of crypto/rand, e.g. /dev/hwrng")
+	requireAllKinds    = flag.Bool("require-all-kinds", false, "Require every kind of -k to appear, placed without bias instead of regenerating")
+	noAmbiguous        = flag.Bool("no-ambiguous", false, "Exclude characters confusable in print or over the phone, such as 0/O and 1/l/I")
+	chars              = flag.String("chars", "", "Use exactly the characters as candidates (overrides -k)")
+	extraChars         = flag.String("extra-chars", "", "Add the characters to candidates of -k")
+	exclude            = flag.String("exclude", "", "Remove the characters from candidates")
+	wordlist           = flag.String("wordlist", "", "Wordlist of -words (english, japanese, eff-long, eff-short), or the file of words, one per line")
+	pattern            = flag.String("pattern", "", "Generate passwords by the hashcat style mask, e.g. '?u?l?l?l?d?d-?s' (overrides -k and -l)")
+	pronounceable      = flag.Bool("pronounceable", false, "Generate pronounceable passwords from phonemes like pwgen, upper cases, numbers and symbols are mixed by -k")
+	hyphenate          = flag.Bool("hyphenate", false, "Print syllables of -pronounceable and -markov passwords after them, like APG")
+	markov             = flag.String("markov", "", "Generate passwords from the n-gram model trained by the wordlist (english, japanese, eff-long, eff-short) or the file of words")
+	markovOrder        = flag.Int("markov-order", 3, "Number of preceding characters of -markov")
+	separator          = flag.String("separator", "-", "Separator of words of -words, and groups of -recovery-codes")
+	capitalize         = flag.String("capitalize", "none", "Capitalization of words of -words (none, first, random, all)")
+	injectDigits       = flag.Int("inject-digits", 0, "Insert the number of digits at random positions of -words passphrases")
+	injectSymbols      = flag.Int("inject-symbols", 0, "Insert the number of symbols at random positions of -words passphrases")
+	bip39              = flag.Int("bip39", 0, "Generate BIP39 mnemonics of the number of words (12, 15, 18, 21, 24)")
+	bip39Wordlist      = flag.String("bip39-wordlist", "", "BIP39 wordlist file of -bip39 in another language, 2048 words one per line, the official English one if empty")
+	bits               = flag.Float64("bits", 0, "Use the minimum length of password, or number of -words, for the bits of entropy (overrides -l)")
+	pin                = flag.Int("pin", 0, "Generate PINs of the number of digits (overrides -k and -l)")
+	safePin            = flag.Bool("safe-pin", false, "Reject trivial PINs of repeated patterns, ascending or descending runs and common years")
+	recoveryCodes      = flag.Int("recovery-codes", 0, "Generate sets of the number of distinct recovery codes like xxxxx-xxxxx, -n sets (ignores -k and -l)")
+	recoveryGroupSize  = flag.Int("recovery-group-size", 5, "Number of characters of each group of -recovery-codes")
+	recoveryGroups     = flag.Int("recovery-groups", 2, "Number of groups of -recovery-codes")
+	recoveryAlphabet   = flag.String("recovery-alphabet", "", "Characters of -recovery-codes, lower cases and digits without ambiguous ones if empty")
+	group              = flag.Int("group", 0, "Print passwords in groups of the number of characters, entropy is of the raw characters")
+	groupSep           = flag.String("group-sep", "-", "Separator of -group")
+	token              = flag.Bool("token", false, "Generate API tokens of base62 bodies of -l characters, like GitHub tokens")
+	tokenPrefix        = flag.String("token-prefix", "", "Prefix of -token and -verify-token, e.g. ghp_ and sk_live_")
+	tokenChecksum      = flag.Bool("token-checksum", false, "Append the base62 CRC32 checksum of the body to -token")
+	verifyToken        = flag.String("verify-token", "", "Verify the prefix and checksum of the token, like -token -token-checksum, and exit")
+	encoding           = flag.String("encoding", "", "Encode -l random bytes instead of drawing characters (hex, base64, base64url, base58)")
+	uuid               = flag.String("uuid", "", "Generate UUIDs of the version (v4, v7) instead of passwords")
+	ulid               = flag.Bool("ulid", false, "Generate ULIDs instead of passwords")
+	preset             = flag.String("preset", "", "Apply the preset of -gen profiles, overridden by flags given explicitly, or list them by list")
+	showEntropy        = flag.Bool("show-entropy", false, "Print the theoretical entropy in bits for each password, as in -meta-out")
+	minEntropy         = flag.Float64("min-entropy", 0, "Fail if the theoretical entropy of passwords is lower than the bits")
+	check              = flag.Bool("check", false, "Estimate strength of passwords of arguments, or lines of stdin, instead of generating")
+	policyFile         = flag.String("policy", "", "Generate passwords satisfying the policy file, or check passwords against it by validate")
+	policyPreset       = flag.String("policy-preset", "", "Same as -policy by the policy of the -gen profile ("+strings.Join(gotpasswd.ProfileNames(), ", ")+")")
+	hibp               = flag.Bool("hibp", false, "Regenerate passwords found in breaches by the Have I Been Pwned range API, sending 5 characters of SHA-1, also for -check")
+	hibpDump           = flag.String("hibp-dump", "", "Search the local Pwned Passwords dump of SHA1:COUNT lines ordered by hash, instead of the API of -hibp")
+	noDictWords        = flag.Bool("no-dict-words", false, "Reject passwords embedding dictionary words of 4 or more letters, ignoring cases and l33t substitutions")
+	dictWordlist       = flag.String("dict-wordlist", "", "File of words of -no-dict-words, one per line, instead of the embedded ones")
+	maxWalk            = flag.Int("max-walk", 0, "Reject runs of adjacent keys (qwerty, qwertz, azerty, dvorak) or sequential characters longer than the length")
+	startWith          = flag.String("start-with", "", "Kinds which the first character must be one of, such as letter, digit, alnum or kind names")
+	endWith            = flag.String("end-with", "", "Kinds which the last character must be one of, such as letter, digit, alnum or kind names")
+	safe               = flag.String("safe", "", "Exclude characters needing quotes or escapes in the contexts, comma separated ("+strings.Join(gotpasswd.UnsafeContexts(), ", ")+")")
+	unique             = flag.Bool("unique", false, "Do not generate the same password twice in a run, failing if -n exceeds the possible passwords")
+	format             = flag.String("format", "text", "Output format of passwords, text, json, jsonl of an object per line or csv; options decorating lines such as -group apply to text only")
+	columns            = flag.String("columns", "", "Comma separated columns of -format csv (index, label, password, length, kinds, entropy, crack_estimate, fuzzy_hash), index,password,entropy if empty")
+	print0             = flag.Bool("print0", false, "Terminate passwords by NUL instead of newlines, for xargs -0 and passwords with spaces")
+	tmpl               = flag.String("template", "", "Print each password by the Go text/template, with .Index, .Label, .Password, .Length, .Kinds, .Entropy and vars of -var, e.g. '{{.Index}},{{.User}},{{.Password}}'")
+	outPath            = flag.String("o", "", "Write passwords to the file of 0600 instead of stdout, created when all of them are written")
+	force              = flag.Bool("force", false, "Overwrite the existing file of -o")
+	fsync              = flag.Bool("fsync", false, "Flush the file of -o to the disk before exiting")
+	historyPath        = flag.String("history", "", "Regenerate passwords recorded in the history file, and record new ones by salted PBKDF2 hashes, e.g. ~/.local/share/gotpasswd/history.json")
+	quiet              = flag.Bool("quiet", false, "Do not print the password copied by -copy, leaving it out of terminal scrollback")
+	qr                 = flag.Bool("qr", false, "Print a QR code of each password, black on white by colors of terminals, for provisioning mobile devices")
+	qrPNG              = flag.String("qr-png", "", "Write a QR code of the password to the file as a PNG image")
+	spell              = flag.Bool("spell", false, "Print the spell-out of each character after the password, like Alfa lower, SEVEN, dollar, for reading over the phone")
+	reveal             = flag.Bool("reveal", false, "Show passwords masked on the terminal, reveal each one by Enter and mask it again by another Enter, for screen sharing")
+	listen             = flag.String("listen", "127.0.0.1:8080", "Address of the serve subcommand")
+	showVersion        = flag.Bool("version", false, "Print the version, commit and build date, as JSON by -format json")
+	noConfig           = flag.Bool("no-config", false, "Ignore the config file of defaults, ~/.config/gotpasswd/config.toml")
+	debug              = flag.Bool("debug", false, "DO NOT USE THIS")
 )
 
 var (
-	dict map[CharacterKind]([]rune)
+	gens         genSpecs
+	requireOneOf stringsFlag
+	minCounts    = kindCounts{}
+	maxCounts    = kindCounts{}
+	vars         = templateVars{}
+	copySeconds  = &copyFlag{}
 )
 
 func init() {
-	dict = make(map[CharacterKind]([]rune))
-	// Auto generate dictionary using ascii printable characters
-	for code := 0x20; code <= 0x7e; code++ {
-		r := rune(code)
-		if !unicode.IsPrint(r) {
-			panic("Internal error, cannot construct character dictionary")
-		}
-
-		// if r == '_' {
-		// 	fmt.Printf("unicode.IsControl('%c') = %v\n", r, unicode.IsControl(r))
-		// 	fmt.Printf("unicode.IsDigit('%c') = %v\n", r, unicode.IsDigit(r))
-		// 	fmt.Printf("unicode.IsGraphic('%c') = %v\n", r, unicode.IsGraphic(r))
-		// 	fmt.Printf("unicode.IsLetter('%c') = %v\n", r, unicode.IsLetter(r))
-		// 	fmt.Printf("unicode.IsLower('%c') = %v\n", r, unicode.IsLower(r))
-		// 	fmt.Printf("unicode.IsMark('%c') = %v\n", r, unicode.IsMark(r))
-		// 	fmt.Printf("unicode.IsNumber('%c') = %v\n", r, unicode.IsNumber(r))
-		// 	fmt.Printf("unicode.IsPrint('%c') = %v\n", r, unicode.IsPrint(r))
-		// 	fmt.Printf("unicode.IsPunct('%c') = %v\n", r, unicode.IsPunct(r))
-		// 	fmt.Printf("unicode.IsSpace('%c') = %v\n", r, unicode.IsSpace(r))
-		// 	fmt.Printf("unicode.IsSymbol('%c') = %v\n", r, unicode.IsSymbol(r))
-		// 	fmt.Printf("unicode.IsTitle('%c') = %v\n", r, unicode.IsTitle(r))
-		// 	fmt.Printf("unicode.IsUpper('%c') = %v\n", r, unicode.IsUpper(r))
-		// }
-
-		switch {
-		case unicode.IsLetter(r):
-			dict[ALPHABET] = append(dict[ALPHABET], r)
-		case unicode.IsNumber(r):
-			dict[NUMBER] = append(dict[NUMBER], r)
-		case unicode.IsSymbol(r):
-			dict[SYMBOL] = append(dict[SYMBOL], r)
-		case unicode.IsSpace(r):
-			dict[SPACE] = append(dict[SPACE], r)
-		case r == '_':
-			dict[UNDERSCORE] = append(dict[UNDERSCORE], r)
-		}
-	}
+	flag.Var(length, "l", "Length of password, or a range of `lengths` like 12-20 chosen randomly for each password")
+	flag.Var(&requireOneOf, "require-one-of", "Require at least one of the `chars`, can be repeated")
+	flag.Var(minCounts, "min", "Require at least the count of characters of each of the kinds for each `kinds=count`, can be repeated")
+	flag.Var(maxCounts, "max", "Allow at most the count of characters of each of the kinds for each `kinds=count`, can be repeated")
+	flag.BoolVar(print0, "0", false, "Same as -print0")
+	flag.IntVar(words, "w", 0, "Same as -words")
+	flag.Var(vars, "var", "Set the var of -template for each `name=value`, referred as .Name, can be repeated")
+	flag.Var(copySeconds, "copy", "Copy the password to the clipboard and clear it after 45 seconds, or -copy=`seconds`")
+	flag.Var(&gens, "gen", "Generate passwords for each `profile=count`, can be repeated ("+strings.Join(gotpasswd.ProfileNames(), ", ")+")")
 }
 
-type Config struct {
-	Kinds  []CharacterKind
-	Length int
-	Num    int
+type generateJob struct {
+	label  string
+	config *gotpasswd.Config
+	num    int
 }
 
-func (self *Config) ParseKinds(s string) ([]CharacterKind, error) {
-	kinds := make([]CharacterKind, 0)
-	for _, candidate := range strings.Split(s, ",") {
-		switch candidate {
-		case "alphabet":
-			kinds = append(kinds, ALPHABET)
-		case "number":
-			kinds = append(kinds, NUMBER)
-		case "symbol":
-			kinds = append(kinds, SYMBOL)
-		case "underscore":
-			kinds = append(kinds, UNDERSCORE)
-		case "space":
-			kinds = append(kinds, SPACE)
-		default:
-			return kinds, errors.New(fmt.Sprintf("Unknown character kind: %s", candidate))
-		}
-	}
-	return kinds, nil
-}
+func _main() int {
+	if spec := os.Getenv(clipboardClearEnv); spec != "" {
+		return clearClipboardLater(spec)
+	}
+	flag.Usage = usage
+	command, err := parseCommand(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 128
+	}
+	if *showVersion {
+		if err := WriteVersion(os.Stdout, *format); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 128
+		}
+		return 0
+	}
+	switch command {
+	case "uuid":
+		if flag.NArg() != 0 {
+			fmt.Fprintln(os.Stderr, "uuid takes at most a version")
+			return 128
+		}
+	case "tui":
+		if err := NewTUI(gotpasswd.NewGenerator(nil)).Run(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	case "config":
+		if flag.Arg(0) != "init" {
+			fmt.Fprintln(os.Stderr, "config requires init")
+			return 128
+		}
+		// flags may follow init, such as config init -force
+		flag.CommandLine.Parse(flag.Args()[1:])
+		if flag.NArg() != 0 {
+			fmt.Fprintln(os.Stderr, "config init takes no arguments")
+			return 128
+		}
+		path, err := configFilePath()
+		if err == nil {
+			err = InitConfigFile(path, *force)
+		}
+		if os.IsExist(err) {
+			fmt.Fprintf(os.Stderr, "%s already exists, overwrite it by -force\n", path)
+			return 1
+		} else if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %s\n", path)
+		return 0
+	case "completion":
+		if flag.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, "completion requires a shell, one of bash, zsh, fish and powershell")
+			return 128
+		}
+		if err := WriteCompletion(os.Stdout, flag.Arg(0)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 128
+		}
+		return 0
+	case "hash":
+		passwords, err := readPasswords()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		for _, passwd := range passwords {
+			hash, err := HashPassword(passwd)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			fmt.Println(hash)
+		}
+		return 0
+	}
+
+	if *describe {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(gotpasswd.Describe()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
 
-func Generate(config *Config) (string, error) {
-	charCandidates := make([]rune, 0)
-	for _, kindIndex := range config.Kinds {
-		charCandidates = append(charCandidates, dict[kindIndex]...)
+	var pwned *gotpasswd.PwnedValidator
+	if *hibp || *hibpDump != "" {
+		pwned = &gotpasswd.PwnedValidator{DumpPath: *hibpDump}
 	}
 
-	if len(charCandidates) == 0 {
-		return "", errors.New("Internal error, cannot work with empty candidates")
+	if *check {
+		passwords, err := readPasswords()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		for _, passwd := range passwords {
+			estimate := estimator.Estimate(passwd)
+			line := fmt.Sprintf("%s\tscore %d\tguesses 10^%.1f\t%s", passwd, estimate.Score, estimate.GuessesLog10, estimate)
+			if pwned != nil {
+				count, err := pwned.Count(context.Background(), passwd)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					return 1
+				}
+				line += fmt.Sprintf("\tpwned %d times", count)
+			}
+			fmt.Println(line)
+		}
+		return 0
 	}
 
-	chars := make([]rune, config.Length)
-	i := 0
-	for i < config.Length {
-		charIndex, err := rand.Int(rand.Reader, big.NewInt(int64(len(charCandidates))))
+	if command == "validate" {
+		policy, err := LookupPolicy(*policyPreset, *policyFile)
 		if err != nil {
-			return "", err
+			fmt.Fprintln(os.Stderr, err)
+			return 128
+		}
+		status := 0
+		scanner := bufio.NewScanner(os.Stdin)
+		for lineno := 1; scanner.Scan(); lineno++ {
+			// passwords are not printed, they may be real ones
+			for _, reason := range policy.Violations(scanner.Text()) {
+				fmt.Printf("line %d: %s\n", lineno, reason)
+				status = 1
+			}
 		}
-		chars[i] = charCandidates[charIndex.Int64()]
-		i++
+		if err := scanner.Err(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return status
 	}
-	return string(chars), nil
-}
 
-func _main() int {
-	flag.Parse()
+	if *preset == "list" {
+		for _, name := range gotpasswd.ProfileNames() {
+			fmt.Println(name)
+		}
+		return 0
+	}
+
+	if *verifyToken != "" {
+		if !gotpasswd.VerifyToken(*verifyToken, *tokenPrefix) {
+			fmt.Println("invalid")
+			return 1
+		}
+		fmt.Println("valid")
+		return 0
+	}
 
 	if *debug {
-		fmt.Fprintf(os.Stderr, "alphabet chars: %v\n", dict[ALPHABET])
-		fmt.Fprintf(os.Stderr, "number chars: %v\n", dict[NUMBER])
-		fmt.Fprintf(os.Stderr, "symbol chars: %v\n", dict[SYMBOL])
-		fmt.Fprintf(os.Stderr, "underscore chars: %v\n", dict[UNDERSCORE])
-		fmt.Fprintf(os.Stderr, "space chars: %v\n", dict[SPACE])
+		for _, kind := range []gotpasswd.CharacterKind{gotpasswd.UPPER, gotpasswd.LOWER, gotpasswd.NUMBER, gotpasswd.SYMBOL, gotpasswd.UNDERSCORE, gotpasswd.SPACE, gotpasswd.EMOJI} {
+			fmt.Fprintf(os.Stderr, "%s chars: %v\n", kind, gotpasswd.Candidates(&gotpasswd.Config{Kinds: []gotpasswd.CharacterKind{kind}}))
+		}
 	}
 
-	config := &Config{}
+	config := &gotpasswd.Config{}
 	if parsed, err := config.ParseKinds(*kinds); err == nil {
 		config.Kinds = parsed
 	} else {
 		fmt.Fprintln(os.Stderr, err)
 		return 128
 	}
-	if *length > 0 {
-		config.Length = *length
-	} else {
-		fmt.Fprintln(os.Stderr, "Length of password must be positive")
-		return 128
+	if *chars != "" {
+		config.Chars = []rune(*chars)
+	}
+	if *extraChars != "" {
+		config.ExtraChars = []rune(*extraChars)
+	}
+	if *exclude != "" {
+		config.Exclude = []rune(*exclude)
+	}
+	config.Length = length.min
+	if length.max > length.min {
+		if *allowedLengths != "" {
+			fmt.Fprintln(os.Stderr, "A range of -l cannot be used with -allowed-lengths")
+			return 128
+		}
+		for allowed := length.min; allowed <= length.max; allowed++ {
+			config.AllowedLengths = append(config.AllowedLengths, allowed)
+		}
+	}
+	if *allowedLengths != "" {
+		for _, s := range strings.Split(*allowedLengths, ",") {
+			allowed, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil || allowed <= 0 {
+				fmt.Fprintf(os.Stderr, "Allowed length must be positive: %s\n", s)
+				return 128
+			}
+			if len(config.AllowedLengths) == 0 || allowed < config.Length {
+				config.Length = allowed
+			}
+			config.AllowedLengths = append(config.AllowedLengths, allowed)
+		}
+	}
+	if *pin != 0 {
+		if *pin <= 0 || *chars != "" || *allowedLengths != "" {
+			fmt.Fprintln(os.Stderr, "Length of -pin must be positive, and -pin cannot be used with -chars nor -allowed-lengths")
+			return 128
+		}
+		config.Kinds = []gotpasswd.CharacterKind{gotpasswd.NUMBER}
+		config.Length = *pin
+		config.AllowedLengths = nil
 	}
 	if *num > 0 {
 		config.Num = *num
@@ -153,16 +377,834 @@ func _main() int {
 		return 128
 	}
 
-	for i := 0; i < config.Num; i++ {
-		passwd, err := Generate(config)
+	config.Layout = *layout
+	config.NoShift = *noShift
+	if *sqlSafe != "" {
+		// literals are escaped at the output instead
+		if *sqlLiteral {
+			if !slices.Contains(gotpasswd.SQLDialects(), *sqlSafe) {
+				fmt.Fprintf(os.Stderr, "Unknown SQL dialect: %s\n", *sqlSafe)
+				return 128
+			}
+		} else {
+			config.SQLSafe = *sqlSafe
+		}
+	} else if *sqlLiteral {
+		fmt.Fprintln(os.Stderr, "-sql-literal requires -sql-safe")
+		return 128
+	}
+	config.Solver = *solver
+	config.NoLeetWords = *noLeetWords
+	config.NoTrivialPins = *safePin
+	config.MaxWalk = *maxWalk
+	if *noDictWords {
+		config.NoDictWords = true
+		if *dictWordlist != "" {
+			loaded, err := gotpasswd.LoadWordlist(*dictWordlist)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 128
+			}
+			config.DictWords = loaded
+		}
+	} else if *dictWordlist != "" {
+		fmt.Fprintln(os.Stderr, "-dict-wordlist requires -no-dict-words")
+		return 128
+	}
+	config.FontSafe = *fontSafe
+	if *safe != "" {
+		config.SafeFor = strings.Split(*safe, ",")
+	}
+	config.NoAmbiguous = *noAmbiguous
+	config.TargetEncoding = *targetEncoding
+	if *grammar != "" {
+		parsed, err := gotpasswd.ParseGrammar(*grammar)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 128
+		}
+		config.Grammar = parsed
+	}
+	if *pattern != "" {
+		if config.Grammar != nil {
+			fmt.Fprintln(os.Stderr, "-pattern cannot be used with -grammar")
+			return 128
+		}
+		parsed, err := gotpasswd.ParseMask(*pattern)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 128
+		}
+		config.Grammar = parsed
+	}
+	if *positions != "" {
+		if config.Grammar != nil {
+			fmt.Fprintln(os.Stderr, "-positions cannot be used with -grammar")
+			return 128
+		}
+		parsed, err := gotpasswd.ParsePositions(*positions)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 128
+		}
+		config.Positions = parsed
+	}
+	if *charsetWeighted != "" {
+		if config.Grammar != nil || config.Positions != nil {
+			fmt.Fprintln(os.Stderr, "-charset-weighted cannot be used with -grammar nor -positions")
+			return 128
+		}
+		parsed, err := gotpasswd.ParseWeightedCharsets(*charsetWeighted)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 128
+		}
+		config.WeightedCharsets = parsed
+	}
+	if *words != 0 || *pinSuffix != 0 {
+		if *words <= 0 || *pinSuffix < 0 {
+			fmt.Fprintln(os.Stderr, "Number of words must be positive, and number of PIN digits must not be negative")
+			return 128
+		}
+		if config.Grammar != nil || config.WeightedCharsets != nil || config.Positions != nil {
+			fmt.Fprintln(os.Stderr, "-words cannot be used with -grammar, -positions nor -charset-weighted")
+			return 128
+		}
+		config.Words = *words
+		config.PinSuffix = *pinSuffix
+		config.Separator = separator
+		config.Capitalize = *capitalize
+		config.InjectDigits = *injectDigits
+		config.InjectSymbols = *injectSymbols
+	} else if (*separator != "-" && *recoveryCodes == 0) || *capitalize != "none" || *injectDigits != 0 || *injectSymbols != 0 {
+		fmt.Fprintln(os.Stderr, "-separator, -capitalize, -inject-digits and -inject-symbols require -words")
+		return 128
+	}
+	if *wordlist != "" {
+		if config.Words == 0 {
+			fmt.Fprintln(os.Stderr, "-wordlist requires -words")
+			return 128
+		}
+		if gotpasswd.IsWordlist(*wordlist) {
+			config.Wordlist = *wordlist
+		} else {
+			loaded, err := gotpasswd.LoadWordlist(*wordlist)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 128
+			}
+			config.CustomWordlist = loaded
+			if len(loaded) > 0 {
+				fmt.Fprintf(os.Stderr, "Loaded %d words from %s, %.2f bits per word\n", len(loaded), *wordlist, math.Log2(float64(len(loaded))))
+			}
+		}
+	}
+	if *pronounceable {
+		if config.Grammar != nil || config.WeightedCharsets != nil || config.Positions != nil || config.Words > 0 {
+			fmt.Fprintln(os.Stderr, "-pronounceable cannot be used with -grammar, -positions, -charset-weighted nor -words")
+			return 128
+		}
+		config.Pronounceable = true
+	}
+	if *markov != "" {
+		if config.Grammar != nil || config.WeightedCharsets != nil || config.Positions != nil || config.Words > 0 || config.Pronounceable {
+			fmt.Fprintln(os.Stderr, "-markov cannot be used with -grammar, -positions, -charset-weighted, -words nor -pronounceable")
+			return 128
+		}
+		model, err := gotpasswd.LoadMarkovModel(*markov, *markovOrder)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 128
+		}
+		config.Markov = model
+	}
+	if *bip39 != 0 || *bip39Wordlist != "" {
+		if config.Grammar != nil || config.WeightedCharsets != nil || config.Positions != nil || config.Words > 0 || config.Pronounceable || config.Markov != nil {
+			fmt.Fprintln(os.Stderr, "-bip39 cannot be used with -grammar, -positions, -charset-weighted, -words, -pronounceable nor -markov")
+			return 128
+		}
+		if *bip39Wordlist != "" {
+			loaded, err := gotpasswd.LoadWordlist(*bip39Wordlist)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 128
+			}
+			if official, err := gotpasswd.VerifyMnemonicWordlist(loaded); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", *bip39Wordlist, err)
+				return 128
+			} else if !official {
+				fmt.Fprintf(os.Stderr, "Warning: %s cannot be verified as an official BIP39 wordlist, wallets must use the same one to restore the mnemonics\n", *bip39Wordlist)
+			}
+			config.MnemonicWordlist = loaded
+		}
+		config.Mnemonic = *bip39
+	}
+	if *token {
+		if config.Grammar != nil || config.WeightedCharsets != nil || config.Positions != nil || config.Words > 0 || config.Pronounceable || config.Markov != nil || config.Mnemonic > 0 {
+			fmt.Fprintln(os.Stderr, "-token cannot be used with -grammar, -positions, -charset-weighted, -words, -pronounceable, -markov nor -bip39")
+			return 128
+		}
+		config.Token = &gotpasswd.TokenFormat{Prefix: *tokenPrefix, Checksum: *tokenChecksum}
+	} else if *tokenPrefix != "" || *tokenChecksum {
+		fmt.Fprintln(os.Stderr, "-token-prefix and -token-checksum require -token")
+		return 128
+	}
+	if *encoding != "" {
+		if config.Grammar != nil || config.WeightedCharsets != nil || config.Positions != nil || config.Words > 0 || config.Pronounceable || config.Markov != nil || config.Mnemonic > 0 || config.Token != nil {
+			fmt.Fprintln(os.Stderr, "-encoding cannot be used with -grammar, -positions, -charset-weighted, -words, -pronounceable, -markov, -bip39 nor -token")
+			return 128
+		}
+		config.RawEncoding = *encoding
+	}
+	if *uuid != "" || *ulid {
+		if config.Grammar != nil || config.WeightedCharsets != nil || config.Positions != nil || config.Words > 0 || config.Pronounceable || config.Markov != nil || config.Mnemonic > 0 || config.Token != nil || config.RawEncoding != "" || (*uuid != "" && *ulid) {
+			fmt.Fprintln(os.Stderr, "-uuid and -ulid cannot be used with each other nor other modes")
+			return 128
+		}
+		if *ulid {
+			config.ID = "ulid"
+		} else {
+			config.ID = "uuid" + strings.TrimPrefix(*uuid, "v")
+		}
+	}
+	if *hyphenate && !config.Pronounceable && config.Markov == nil {
+		fmt.Fprintln(os.Stderr, "-hyphenate requires -pronounceable or -markov")
+		return 128
+	}
+	if *noProfanity || *profanityList != "" {
+		config.Blocklist = gotpasswd.Profanity()
+		if *profanityList != "" {
+			loaded, err := gotpasswd.LoadBlocklist(*profanityList)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 128
+			}
+			config.Blocklist = append(loaded, config.Blocklist...)
+		}
+	}
+	if *againstRegexFile != "" {
+		loaded, err := gotpasswd.LoadPatterns(*againstRegexFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 128
+		}
+		config.Patterns = loaded
+	}
+	for _, group := range requireOneOf {
+		config.RequireOneOf = append(config.RequireOneOf, []rune(group))
+	}
+	if len(minCounts) > 0 {
+		config.MinCounts = minCounts
+	}
+	if len(maxCounts) > 0 {
+		config.MaxCounts = maxCounts
+	}
+	config.RequireAllKinds = *requireAllKinds
+	config.NoRepeatedBigrams = *noRepeatedBigrams
+	config.NoRepeat = *noRepeat
+	for _, edge := range []struct {
+		name  string
+		value string
+		kinds *[]gotpasswd.CharacterKind
+	}{{"start-with", *startWith, &config.StartWith}, {"end-with", *endWith, &config.EndWith}} {
+		if edge.value == "" {
+			continue
+		}
+		kinds, err := config.ParseEdgeKinds(edge.value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "-%s: %s\n", edge.name, err)
+			return 128
+		}
+		*edge.kinds = kinds
+	}
+	config.NoAdjacentRepeat = *noAdjacentRepeat
+	config.MinTransitions = *minTransitions
+	config.MinCaseChanges = *minCaseChanges
+	config.MinShannon = *minShannon
+	if *kindWindow != "" {
+		window, err := gotpasswd.ParseKindWindow(*kindWindow)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 128
+		}
+		config.KindWindow = window
+	}
+	if *validatorCmd != "" {
+		validator, err := gotpasswd.NewCommandValidator(*validatorCmd)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 128
+		}
+		config.Validator = validator
+	}
+	config.MaxDistinctSymbols = *maxDistinctSymbols
+	config.MinEntropy = *minEntropy
+	if *preset != "" {
+		profile, ok := gotpasswd.LookupProfile(*preset)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Unknown preset: %s, one of %s\n", *preset, strings.Join(gotpasswd.ProfileNames(), ", "))
+			return 128
+		}
+		// flags given explicitly override the preset
+		presetConfig := *config
+		profile.Apply(&presetConfig)
+		if !commandLineFlags["k"] && !commandLineFlags["pin"] {
+			config.Kinds = presetConfig.Kinds
+		}
+		if !commandLineFlags["l"] && !commandLineFlags["allowed-lengths"] && !commandLineFlags["pin"] {
+			config.Length = presetConfig.Length
+		}
+		config.MinKinds = presetConfig.MinKinds
+		config.MinCounts = presetConfig.MinCounts
+		config.NoAmbiguous = presetConfig.NoAmbiguous
+	}
+	if *policyFile != "" || *policyPreset != "" {
+		if config.Validator != nil {
+			fmt.Fprintln(os.Stderr, "-policy and -policy-preset cannot be used with -validator-cmd")
+			return 128
+		}
+		policy, err := LookupPolicy(*policyPreset, *policyFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 128
+		}
+		policy.Apply(config)
+	}
+	if pwned != nil {
+		if config.Validator != nil {
+			config.Validator = gotpasswd.Validators{config.Validator, pwned}
+		} else {
+			config.Validator = pwned
+		}
+	}
+	var history *History
+	if *historyPath != "" {
+		var err error
+		if history, err = LoadHistory(*historyPath); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 128
+		}
+		// checked first, not to send reissued passwords to other validators
+		if config.Validator != nil {
+			config.Validator = gotpasswd.Validators{history, config.Validator}
+		} else {
+			config.Validator = history
+		}
+	}
+	if *bits != 0 {
+		if *bits < 0 || config.AllowedLengths != nil {
+			fmt.Fprintln(os.Stderr, "-bits must be positive, and cannot be used with -allowed-lengths")
+			return 128
+		}
+		if err := config.FitEntropy(*bits); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 128
+		}
+		if config.Words > 0 {
+			fmt.Fprintf(os.Stderr, "%d words for %.1f bits of entropy\n", config.Words, gotpasswd.Entropy(config))
+		} else {
+			fmt.Fprintf(os.Stderr, "Length %d for %.1f bits of entropy\n", config.Length, gotpasswd.Entropy(config))
+		}
+	}
+	if err := config.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 128
+	}
+	if command == "charset" {
+		fmt.Println(string(gotpasswd.Candidates(config)))
+		return 0
+	}
+
+	var source io.Reader
+	if *deriveSalt != "" {
+		if *secret == "" || *site == "" {
+			fmt.Fprintln(os.Stderr, "-derive-salt requires -secret and -site")
+			return 128
+		}
+		if *timeBucket != 0 || *seed != "" {
+			fmt.Fprintln(os.Stderr, "-derive-salt cannot be used with -time-bucket nor -seed")
+			return 128
+		}
+		derived, err := NewDerivedReader(*deriveSalt, *site, []byte(*secret), *rotateSalt)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		source = derived
+	} else if *timeBucket != 0 || *secret != "" {
+		if *timeBucket <= 0 || *secret == "" {
+			fmt.Fprintln(os.Stderr, "Both of positive -time-bucket and -secret are required")
+			return 128
+		}
+		source = NewTimeBucketReader([]byte(*secret), *timeBucket, time.Now())
+	}
+	if from, ok := flagSources["secret"]; ok && source != nil {
+		fmt.Fprintf(os.Stderr, "Warning: -secret is set by %s, passwords are NOT random, anyone with the secret can reproduce them\n", from)
+	}
+	if *seed != "" {
+		if source != nil {
+			fmt.Fprintln(os.Stderr, "-seed cannot be used with -time-bucket")
+			return 128
+		}
+		// the command line shows no hint of it
+		if from, ok := flagSources["seed"]; ok {
+			fmt.Fprintf(os.Stderr, "Warning: -seed is set by %s, passwords are NOT random, anyone with the seed can reproduce them\n", from)
+		}
+		source = NewSeededReader(*seed)
+	}
+	if *randSource != "" {
+		if source != nil {
+			fmt.Fprintln(os.Stderr, "-rand-source cannot be used with -seed, -time-bucket nor -derive-salt")
+			return 128
+		}
+		file, err := os.Open(*randSource)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer file.Close()
+		source = file
+	}
+	if *share {
+		if *seed == "" {
+			fmt.Fprintln(os.Stderr, "-share requires -seed")
+			return 128
+		}
+		fmt.Fprintln(os.Stderr, "Warning: passwords generated with -seed are NOT secret, anyone with the command can reproduce them")
+		fmt.Fprintln(os.Stderr, ShareCommand(flag.CommandLine))
+	}
+	generator := gotpasswd.NewGenerator(source)
+
+	jobs := []*generateJob{{config: config, num: config.Num}}
+	if len(gens) > 0 {
+		jobs = jobs[:0]
+		for _, spec := range gens {
+			profile, ok := gotpasswd.LookupProfile(spec.profile)
+			if !ok {
+				fmt.Fprintf(os.Stderr, "Unknown profile: %s\n", spec.profile)
+				return 128
+			}
+			profileConfig := *config
+			profile.Apply(&profileConfig)
+			if err := profileConfig.Validate(); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", spec.profile, err)
+				return 128
+			}
+			jobs = append(jobs, &generateJob{label: spec.profile, config: &profileConfig, num: spec.count})
+		}
+	}
+	var services []*ManifestService
+	if *manifest != "" {
+		if len(gens) > 0 {
+			fmt.Fprintln(os.Stderr, "-manifest cannot be used with -gen")
+			return 128
+		}
+		var err error
+		services, err = LoadManifest(*manifest)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 128
+		}
+		for _, service := range services {
+			serviceConfig := *config
+			service.Apply(&serviceConfig)
+			if err := serviceConfig.Validate(); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", service.Name, err)
+				return 128
+			}
+		}
+	}
+
+	total := 0
+	for _, job := range jobs {
+		total += job.num
+	}
+
+	if copySeconds.seconds > 0 && total != 1 {
+		fmt.Fprintln(os.Stderr, "-copy can copy only a single password, -n must be 1")
+		return 128
+	} else if *qrPNG != "" && total != 1 {
+		fmt.Fprintln(os.Stderr, "-qr-png can write only a single password, -n must be 1")
+		return 128
+	} else if *qr && *format != "text" {
+		fmt.Fprintln(os.Stderr, "-qr cannot be used with -format other than text")
+		return 128
+	} else if *reveal && (*format != "text" || *tmpl != "" || *outPath != "" || *quiet) {
+		fmt.Fprintln(os.Stderr, "-reveal cannot be used with -format other than text, -template, -o nor -quiet")
+		return 128
+	} else if *quiet && copySeconds.seconds == 0 {
+		fmt.Fprintln(os.Stderr, "-quiet requires -copy")
+		return 128
+	}
+
+	if *badge != "" {
+		file, err := os.Create(*badge)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		err = WriteBadge(file, gotpasswd.Entropy(config))
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	var seen map[string]bool
+	if *unique {
+		for _, job := range jobs {
+			if err := checkKeyspace(job.config, job.num); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 128
+			}
+		}
+		seen = make(map[string]bool)
+	}
+
+	if *group < 0 {
+		fmt.Fprintln(os.Stderr, "Size of -group must not be negative")
+		return 128
+	}
+
+	var meta *MetaWriter
+	if *metaOut != "" {
+		file, err := os.Create(*metaOut)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			return 1
 		}
-		fmt.Println(passwd)
+		defer file.Close()
+		meta = NewMetaWriter(file)
+	}
+
+	var out io.Writer = os.Stdout
+	var outFile *OutputFile
+	if *outPath != "" {
+		var err error
+		outFile, err = CreateOutputFile(*outPath, *force)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer outFile.Discard()
+		outFile.Sync = *fsync
+		out = outFile
+	} else if *force || *fsync {
+		fmt.Fprintln(os.Stderr, "-force and -fsync require -o")
+		return 128
+	}
+
+	var output OutputWriter
+	if *crackEstimate {
+		recordEstimator = estimator
+	}
+	// fuzzy hashes reveal much of passwords, they're only beside the passwords
+	if *ssdeep {
+		if *format == "text" {
+			fmt.Fprintln(os.Stderr, "-ssdeep requires -format json, jsonl or csv")
+			return 128
+		}
+		recordFuzzyHash = true
+	}
+	if *columns != "" && *format != "csv" {
+		fmt.Fprintln(os.Stderr, "-columns requires -format csv")
+		return 128
+	}
+	if *print0 && *format != "text" {
+		fmt.Fprintln(os.Stderr, "-print0 cannot be used with -format other than text")
+		return 128
+	}
+	if *tmpl != "" {
+		if *format != "text" {
+			fmt.Fprintln(os.Stderr, "-template cannot be used with -format other than text")
+			return 128
+		}
+		writer, err := NewTemplateWriter(out, *tmpl, vars)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 128
+		}
+		if *print0 {
+			writer.Terminator = "\x00"
+		}
+		output = writer
+	} else if len(vars) > 0 {
+		fmt.Fprintln(os.Stderr, "-var requires -template")
+		return 128
+	}
+	if *format == "csv" {
+		names := []string{"index", "password", "entropy"}
+		if *columns != "" {
+			names = strings.Split(*columns, ",")
+		} else {
+			if *crackEstimate {
+				names = append(names, "crack_estimate")
+			}
+			if *ssdeep {
+				names = append(names, "fuzzy_hash")
+			}
+		}
+		writer, err := NewCSVWriter(out, names)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 128
+		}
+		output = writer
+	} else if *format != "text" {
+		newWriter, ok := outputFormats[*format]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Unknown output format: %s\n", *format)
+			return 128
+		}
+		output = newWriter(out)
+	}
+
+	if *recoveryCodes != 0 {
+		set := &gotpasswd.RecoveryCodes{
+			Count:     *recoveryCodes,
+			GroupSize: *recoveryGroupSize,
+			Groups:    *recoveryGroups,
+			Alphabet:  []rune(*recoveryAlphabet),
+			Separator: separator,
+		}
+		if err := set.Validate(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 128
+		}
+		index := 0
+		for i := 0; i < config.Num; i++ {
+			codes, err := generator.GenerateRecoveryCodes(set)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			// sets for each account are separated by empty lines
+			if i > 0 && output == nil {
+				fmt.Fprintln(out)
+			}
+			for _, code := range codes {
+				index++
+				if output != nil {
+					if err := output.Write(index, "", gotpasswd.Password{Value: code, Entropy: set.Entropy()}); err != nil {
+						fmt.Fprintln(os.Stderr, err)
+						return 1
+					}
+				} else {
+					fmt.Fprintln(out, code)
+				}
+			}
+		}
+		if output != nil {
+			if err := output.Close(); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+		}
+		if outFile != nil {
+			if err := outFile.Commit(); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+		}
+		return 0
+	}
+
+	var cards CardWriter
+	if *pdf != "" {
+		file, err := os.OpenFile(*pdf, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer file.Close()
+		cards = NewPDFCardWriter(file)
+	}
+
+	// cancel on SIGINT, to finish outputs of passwords already generated
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	if services != nil {
+		return GenerateManifest(ctx, generator, config, services)
+	}
+	if command == "serve" {
+		fmt.Fprintf(os.Stderr, "Serving passwords on http://%s/\n", *listen)
+		if err := Serve(ctx, *listen, generator, config); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+
+	// The date is predictable, it adds no secrecy to passwords
+	dateSuffixText := ""
+	if *dateSuffix != "" {
+		dateSuffixText = "-" + time.Now().Format(*dateSuffix)
+	}
+
+	colorEnabled := *color && outFile == nil && colorSupported(os.Stdout)
+
+	var revealer *Revealer
+	if *reveal {
+		var err error
+		if revealer, err = OpenRevealer(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer revealer.Close()
+	}
+
+	status := 0
+	index := 0
+generation:
+	for _, job := range jobs {
+		for i := 0; i < job.num; i++ {
+			var (
+				passwd gotpasswd.Password
+				err    error
+			)
+			if seen != nil {
+				passwd, err = generateUnique(ctx, generator, job.config, seen)
+			} else {
+				passwd, err = generator.GeneratePasswordContext(ctx, job.config)
+			}
+			if err == context.DeadlineExceeded {
+				fmt.Fprintf(os.Stderr, "Timed out after %s, generated %d of %d passwords\n", *timeout, index, total)
+				status = 1
+				break generation
+			} else if err == context.Canceled {
+				fmt.Fprintf(os.Stderr, "Interrupted, generated %d of %d passwords\n", index, total)
+				status = 1
+				break generation
+			} else if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			index++
+			if history != nil {
+				if err := history.Add(passwd.Value); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					return 1
+				}
+			}
+			passwd.Value += dateSuffixText
+
+			if copySeconds.seconds > 0 {
+				if err := CopyToClipboard(passwd.Value, copySeconds.seconds); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					return 1
+				}
+				fmt.Fprintf(os.Stderr, "Copied to the clipboard, it will be cleared in %d seconds\n", copySeconds.seconds)
+			}
+			if *quiet {
+				// the password is only on the clipboard
+			} else if output != nil {
+				if err := output.Write(index, job.label, passwd); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					return 1
+				}
+			} else {
+				line := passwd.Value
+				if *group > 0 {
+					line = GroupChars(line, *group, *groupSep)
+				}
+				if *sqlLiteral {
+					line = gotpasswd.SQLLiteral(line, *sqlSafe)
+				}
+				if colorEnabled {
+					line = Colorize(line)
+				}
+				if *hyphenate {
+					line += " (" + gotpasswd.Hyphenate(passwd.Value) + ")"
+				}
+				if *spell {
+					line += "\t" + gotpasswd.Spell(passwd.Value)
+				}
+				// the label of -reveal is shown while the password is masked
+				if job.label != "" && revealer == nil {
+					line = job.label + "\t" + line
+				}
+				if *showEntropy {
+					line += fmt.Sprintf("\t%.1f bits", passwd.Entropy)
+				}
+				if *crackEstimate {
+					line += "\t" + estimator.Estimate(passwd.Value).String()
+				}
+				if revealer != nil {
+					if err := revealer.Reveal(job.label, line); err != nil {
+						fmt.Fprintln(os.Stderr, err)
+						return 1
+					}
+				} else if *print0 {
+					fmt.Fprint(out, line+"\x00")
+				} else {
+					fmt.Fprintln(out, line)
+				}
+			}
+			if *qr || *qrPNG != "" {
+				code, err := NewQRCode([]byte(passwd.Value))
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					return 1
+				}
+				if *qr {
+					fmt.Fprint(out, code.Text(outFile == nil && colorSupported(os.Stdout)))
+				}
+				if *qrPNG != "" {
+					if err := writeQRPNG(*qrPNG, code); err != nil {
+						fmt.Fprintln(os.Stderr, err)
+						return 1
+					}
+				}
+			}
+			if meta != nil {
+				if err := meta.Write(index, passwd); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					return 1
+				}
+			}
+			if cards != nil {
+				if err := cards.AddCard(job.label, passwd.Value); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					return 1
+				}
+			}
+		}
+	}
+	if output != nil {
+		if err := output.Close(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	if cards != nil {
+		if err := cards.Close(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	if outFile != nil {
+		if err := outFile.Commit(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	if history != nil {
+		if err := history.Save(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
 	}
 
-	return 0
+	return status
 }
 
 func main() {
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kamichidu/go-gotpasswd"
+)
+
+// The test binary runs _main instead of the tests when this is set, see runMain
+const runMainEnv = "TEST_GOTPASSWD_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) != "" {
+		os.Exit(_main())
+	}
+	os.Exit(m.Run())
+}
+
+// runMain runs the command in a process of its own, since flags are global,
+// with an empty home not to load config files of the user.
+func runMain(t *testing.T, env []string, args ...string) (string, string, int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = []string{runMainEnv + "=1", "HOME=" + t.TempDir()}
+	cmd.Env = append(cmd.Env, env...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return stdout.String(), stderr.String(), exitErr.ExitCode()
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	return stdout.String(), stderr.String(), 0
+}
+
+func TestRecoveryCodesOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "codes.txt")
+	stdout, stderr, status := runMain(t, nil, "-recovery-codes", "4", "-n", "2", "-o", path)
+	if status != 0 {
+		t.Fatalf("status = %d, stderr = %q", status, stderr)
+	}
+	if stdout != "" {
+		t.Errorf("stdout = %q, want the codes only in the file", stdout)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sets := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n\n")
+	if len(sets) != 2 {
+		t.Fatalf("%q must have 2 sets", content)
+	}
+	for _, set := range sets {
+		if codes := strings.Split(set, "\n"); len(codes) != 4 {
+			t.Errorf("%q must have 4 codes", set)
+		}
+	}
+
+	stdout, stderr, status = runMain(t, nil, "-recovery-codes", "3", "-format", "jsonl")
+	if status != 0 {
+		t.Fatalf("status = %d, stderr = %q", status, stderr)
+	}
+	if lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n"); len(lines) != 3 || !strings.HasPrefix(lines[0], `{"index":1,`) {
+		t.Errorf("stdout = %q, want 3 records", stdout)
+	}
+}
+
+func TestSeedSourceWarning(t *testing.T) {
+	home := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(home, ".config", "gotpasswd"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	config := filepath.Join(home, ".config", "gotpasswd", "config.toml")
+	if err := os.WriteFile(config, []byte("seed = \"config\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		env     []string
+		args    []string
+		warning string
+	}{
+		{nil, []string{"-seed", "flag"}, ""},
+		{[]string{"GOTPASSWD_SEED=env"}, nil, "Warning: -seed is set by an environment variable"},
+		{[]string{"HOME=" + home}, nil, "Warning: -seed is set by " + config},
+		// the command line wins over the config file
+		{[]string{"HOME=" + home}, []string{"-seed", "flag"}, ""},
+	} {
+		stdout, stderr, status := runMain(t, c.env, c.args...)
+		if status != 0 || stdout == "" {
+			t.Fatalf("status = %d, stderr = %q", status, stderr)
+		}
+		if c.warning == "" && stderr != "" {
+			t.Errorf("%v %v: stderr = %q, want no warnings", c.env, c.args, stderr)
+		} else if !strings.HasPrefix(stderr, c.warning) {
+			t.Errorf("%v %v: stderr = %q, want %q", c.env, c.args, stderr, c.warning)
+		}
+	}
+}
+
+func TestSecretSourceWarning(t *testing.T) {
+	saltFile := filepath.Join(t.TempDir(), "salts.json")
+	for _, c := range []struct {
+		env     []string
+		args    []string
+		warning string
+	}{
+		{nil, []string{"-time-bucket", "1h", "-secret", "flag"}, ""},
+		{[]string{"GOTPASSWD_SECRET=env"}, []string{"-time-bucket", "1h"}, "Warning: -secret is set by an environment variable"},
+		{[]string{"GOTPASSWD_SECRET=env", "GOTPASSWD_DERIVE_SALT=" + saltFile}, []string{"-site", "example.com"}, "Warning: -secret is set by an environment variable"},
+	} {
+		stdout, stderr, status := runMain(t, c.env, c.args...)
+		if status != 0 || stdout == "" {
+			t.Fatalf("status = %d, stderr = %q", status, stderr)
+		}
+		if c.warning == "" && stderr != "" {
+			t.Errorf("%v %v: stderr = %q, want no warnings", c.env, c.args, stderr)
+		} else if !strings.HasPrefix(stderr, c.warning) {
+			t.Errorf("%v %v: stderr = %q, want %q", c.env, c.args, stderr, c.warning)
+		}
+	}
+	// the secret alone is still an error
+	if _, _, status := runMain(t, []string{"GOTPASSWD_SECRET=env"}); status != 128 {
+		t.Errorf("status = %d, want 128 without -time-bucket", status)
+	}
+}
+
+func TestDateSuffix(t *testing.T) {
+	before := time.Now()
+	stdout, stderr, status := runMain(t, nil, "-date-suffix", "2006-01-02", "-k", "alphabet", "-l", "12", "-n", "10")
+	after := time.Now()
+	if status != 0 {
+		t.Fatalf("status = %d, stderr = %q", status, stderr)
+	}
+	pattern := regexp.MustCompile(`^([A-Za-z]{12})-(\d{4}-\d{2}-\d{2})$`)
+	prefixes := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSuffix(stdout, "\n"), "\n") {
+		m := pattern.FindStringSubmatch(line)
+		if m == nil {
+			t.Fatalf("%q is not 12 alphabets and the date", line)
+		}
+		// the run may cross midnight
+		if m[2] != before.Format("2006-01-02") && m[2] != after.Format("2006-01-02") {
+			t.Errorf("date of %q is out of the run from %s to %s", line, before, after)
+		}
+		prefixes[m[1]] = true
+	}
+	if len(prefixes) != 10 {
+		t.Errorf("got %d distinct prefixes, want 10 random ones", len(prefixes))
+	}
+}
+
+func TestPassphraseWordlists(t *testing.T) {
+	for _, c := range []struct {
+		args     []string
+		wordlist string
+		words    int
+	}{
+		{[]string{"passphrase"}, "eff-long", 6},
+		{[]string{"passphrase", "-w", "4", "-wordlist", "eff-short"}, "eff-short", 4},
+		// without the subcommand, -words keeps the english one
+		{[]string{"-w", "5"}, "english", 5},
+	} {
+		stdout, stderr, status := runMain(t, nil, append(c.args, "-separator", " ")...)
+		if status != 0 {
+			t.Fatalf("status = %d, stderr = %q", status, stderr)
+		}
+		candidates := gotpasswd.PassphraseWords(&gotpasswd.Config{Wordlist: c.wordlist})
+		words := strings.Fields(stdout)
+		if len(words) != c.words {
+			t.Fatalf("%v: %q must have %d words", c.args, stdout, c.words)
+		}
+		for _, word := range words {
+			if !slices.Contains(candidates, word) {
+				t.Errorf("%v: %q is not a word of %s", c.args, word, c.wordlist)
+			}
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kamichidu/go-gotpasswd"
+)
+
+// Directory where passwords of manifest services are written
+const manifestSecretsDir = "secrets"
+
+// ManifestService is a service listed in a manifest, with its password policy.
+type ManifestService struct {
+	Name     string
+	Profile  string
+	Kinds    []gotpasswd.CharacterKind
+	Length   int
+	MinKinds int
+}
+
+// Apply overrides the settings of config by the policy of the service.
+func (self *ManifestService) Apply(config *gotpasswd.Config) {
+	if profile, ok := gotpasswd.LookupProfile(self.Profile); ok {
+		profile.Apply(config)
+	}
+	if self.Kinds != nil {
+		config.Kinds = self.Kinds
+	}
+	if self.Length > 0 {
+		config.Length = self.Length
+		config.AllowedLengths = nil
+	}
+	if self.MinKinds > 0 {
+		config.MinKinds = self.MinKinds
+	}
+}
+
+// LoadManifest reads services from a manifest, written in a subset of YAML:
+//
+//	database:
+//	  length: 32
+//	  kinds: alphabet,number
+//	web:
+//	  profile: nist
+func LoadManifest(path string) ([]*ManifestService, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	services := make([]*ManifestService, 0)
+	seen := make(map[string]bool)
+	var service *ManifestService
+	scanner := bufio.NewScanner(file)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i == 0 || (i > 0 && strings.ContainsAny(line[i-1:i], " \t")) {
+			line = line[:i]
+		}
+		line = strings.TrimRight(line, " \t\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fail := func(format string, args ...interface{}) ([]*ManifestService, error) {
+			return nil, errors.New(fmt.Sprintf("%s:%d: %s", path, lineno, fmt.Sprintf(format, args...)))
+		}
+		pair := strings.SplitN(strings.TrimSpace(line), ":", 2)
+		if len(pair) != 2 {
+			return fail("must be key: value")
+		}
+		key := strings.TrimSpace(pair[0])
+		value := strings.Trim(strings.TrimSpace(pair[1]), `"'`)
+
+		if line[0] != ' ' && line[0] != '\t' {
+			if value != "" {
+				return fail("service %s must have a policy, not a value", key)
+			}
+			if key == "" || key == "." || key == ".." || strings.ContainsAny(key, `/\`) {
+				return fail("invalid service name: %s", key)
+			}
+			if seen[key] {
+				return fail("duplicated service: %s", key)
+			}
+			seen[key] = true
+			service = &ManifestService{Name: key}
+			services = append(services, service)
+			continue
+		}
+		if service == nil {
+			return fail("policy must be under a service")
+		}
+		switch key {
+		case "profile":
+			if _, ok := gotpasswd.LookupProfile(value); !ok {
+				return fail("unknown profile: %s", value)
+			}
+			service.Profile = value
+		case "kinds":
+			kinds, err := (&gotpasswd.Config{}).ParseKinds(value)
+			if err != nil {
+				return fail("%s", err)
+			}
+			service.Kinds = kinds
+		case "length", "min-kinds":
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return fail("%s must be positive: %s", key, value)
+			}
+			if key == "length" {
+				service.Length = n
+			} else {
+				service.MinKinds = n
+			}
+		default:
+			return fail("unknown policy: %s", key)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(services) == 0 {
+		return nil, errors.New(fmt.Sprintf("%s: no services", path))
+	}
+	return services, nil
+}
+
+// GenerateManifest generates a password for each service into manifestSecretsDir, and reports results.
+// It returns the exit status, which is 1 if any of services failed.
+func GenerateManifest(ctx context.Context, generator *gotpasswd.Generator, config *gotpasswd.Config, services []*ManifestService) int {
+	if err := os.MkdirAll(manifestSecretsDir, 0700); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	status := 0
+	for _, service := range services {
+		serviceConfig := *config
+		service.Apply(&serviceConfig)
+		path := filepath.Join(manifestSecretsDir, service.Name)
+
+		passwd, err := generator.GeneratePasswordContext(ctx, &serviceConfig)
+		if err == nil {
+			err = os.WriteFile(path, []byte(passwd.Value+"\n"), 0600)
+		}
+		if err == nil {
+			// WriteFile keeps permissions of existing files
+			err = os.Chmod(path, 0600)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\tfailed: %s\n", service.Name, err)
+			status = 1
+			continue
+		}
+		fmt.Printf("%s\t%s\t%.1f bits\n", service.Name, path, passwd.Entropy)
+	}
+	return status
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kamichidu/go-gotpasswd"
+)
+
+func TestGenerateManifest(t *testing.T) {
+	path := writeTestFile(t, `# services
+database:
+  length: 32
+  kinds: number # digits only
+web:
+  profile: wifi-router
+admin:
+  profile: windows-ad
+  length: 20
+`)
+	services, err := LoadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(t.TempDir())
+	config := &gotpasswd.Config{Kinds: []gotpasswd.CharacterKind{gotpasswd.LOWER}, Length: 8}
+	if status := GenerateManifest(context.Background(), gotpasswd.NewGenerator(nil), config, services); status != 0 {
+		t.Fatalf("status is %d", status)
+	}
+
+	for name, check := range map[string]func(passwd string) bool{
+		"database": func(passwd string) bool {
+			return len(passwd) == 32 && strings.Trim(passwd, "0123456789") == ""
+		},
+		"web": func(passwd string) bool {
+			return len(passwd) == 20 && !strings.ContainsAny(passwd, "0O1lI")
+		},
+		"admin": func(passwd string) bool {
+			return len(passwd) == 20 && len(gotpasswd.KindsOf(passwd)) >= 3
+		},
+	} {
+		file := filepath.Join(manifestSecretsDir, name)
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if passwd := strings.TrimSuffix(string(content), "\n"); !check(passwd) {
+			t.Errorf("%s: %q does not meet its policy", name, passwd)
+		}
+		if stat, err := os.Stat(file); err != nil || stat.Mode().Perm() != 0600 {
+			t.Errorf("%s: mode is %v, %v", name, stat.Mode(), err)
+		}
+	}
+}
+
+func TestLoadManifestErrors(t *testing.T) {
+	for _, content := range []string{
+		"",
+		"  length: 8\n",
+		"db: 8\n",
+		"../etc:\n  length: 8\n",
+		"db:\n  length: 8\ndb:\n  length: 9\n",
+		"db:\n  profile: unknown\n",
+		"db:\n  length: -1\n",
+		"db:\n  color: red\n",
+	} {
+		if _, err := LoadManifest(writeTestFile(t, content)); err == nil {
+			t.Errorf("LoadManifest(%q) must fail", content)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/kamichidu/go-gotpasswd"
+)
+
+// MetaWriter writes metadata of passwords as JSON lines, without the passwords themselves.
+type MetaWriter struct {
+	encoder *json.Encoder
+}
+
+type passwordMeta struct {
+	Index       int       `json:"index"`
+	EntropyBits float64   `json:"entropy_bits"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+func NewMetaWriter(w io.Writer) *MetaWriter {
+	return &MetaWriter{
+		encoder: json.NewEncoder(w),
+	}
+}
+
+func (self *MetaWriter) Write(index int, passwd gotpasswd.Password) error {
+	meta := &passwordMeta{
+		Index:       index,
+		EntropyBits: passwd.Entropy,
+		Timestamp:   time.Now(),
+	}
+	return self.encoder.Encode(meta)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMetaOut(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "meta.jsonl")
+	stdout, stderr, status := runMain(t, nil, "-gen", "nist=2", "-gen", "wifi=1", "-format", "jsonl", "-meta-out", path)
+	if status != 0 {
+		t.Fatalf("status = %d, stderr = %q", status, stderr)
+	}
+	var records []passwordRecord
+	scanner := bufio.NewScanner(strings.NewReader(stdout))
+	for scanner.Scan() {
+		var record passwordRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var metas []map[string]interface{}
+	scanner = bufio.NewScanner(file)
+	for scanner.Scan() {
+		var meta map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &meta); err != nil {
+			t.Fatal(err)
+		}
+		metas = append(metas, meta)
+	}
+
+	if len(records) != 3 || len(metas) != 3 {
+		t.Fatalf("got %d passwords and %d metadata, want 3 of each", len(records), len(metas))
+	}
+	// profiles of different entropies tell records apart
+	if records[0].EntropyBits == records[2].EntropyBits {
+		t.Fatal("entropies of nist and wifi must differ")
+	}
+	for i, record := range records {
+		meta := metas[i]
+		if meta["index"] != float64(record.Index) || meta["entropy_bits"] != record.EntropyBits {
+			t.Errorf("metadata %v does not correspond to the password %+v", meta, record)
+		}
+		if _, ok := meta["timestamp"]; !ok {
+			t.Errorf("metadata %v must have the timestamp", meta)
+		}
+		if _, ok := meta["password"]; ok {
+			t.Errorf("metadata %v must not have the password", meta)
+		}
+	}
+
+	// plain lines of stdout correspond to the metadata likewise
+	stdout, stderr, status = runMain(t, nil, "-n", "4", "-meta-out", path)
+	if status != 0 {
+		t.Fatalf("status = %d, stderr = %q", status, stderr)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	passwords := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if len(passwords) != 4 || len(lines) != 4 {
+		t.Fatalf("got %d passwords and %d metadata, want 4 of each", len(passwords), len(lines))
+	}
+	for i, line := range lines {
+		if !strings.HasPrefix(line, fmt.Sprintf(`{"index":%d,`, i+1)) || strings.Contains(line, passwords[i]) {
+			t.Errorf("metadata %s does not correspond to the password %d", line, i+1)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// OutputFile is a temporary file of 0600 in the directory of the path, moved to the path by Commit,
+// so that no partial files are left on failures and existing files are replaced only by force.
+type OutputFile struct {
+	*os.File
+
+	// Flush the file and the directory to the disk on Commit
+	Sync bool
+
+	path      string
+	force     bool
+	committed bool
+}
+
+func CreateOutputFile(path string, force bool) (*OutputFile, error) {
+	if _, err := os.Lstat(path); err == nil && !force {
+		return nil, errors.New(fmt.Sprintf("%s already exists, use -force to overwrite it", path))
+	}
+	// CreateTemp creates files of 0600 regardless of umask
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return nil, err
+	}
+	return &OutputFile{File: file, path: path, force: force}, nil
+}
+
+// Commit moves the file to the path, failing if the path was created meanwhile without force.
+func (self *OutputFile) Commit() error {
+	if self.Sync {
+		if err := self.File.Sync(); err != nil {
+			return err
+		}
+	}
+	if err := self.File.Close(); err != nil {
+		return err
+	}
+	var err error
+	if self.force {
+		err = os.Rename(self.Name(), self.path)
+	} else if err = os.Link(self.Name(), self.path); err == nil {
+		err = os.Remove(self.Name())
+	}
+	if err != nil {
+		return err
+	}
+	self.committed = true
+	if self.Sync {
+		dir, err := os.Open(filepath.Dir(self.path))
+		if err != nil {
+			return err
+		}
+		defer dir.Close()
+		return dir.Sync()
+	}
+	return nil
+}
+
+// Discard removes the file unless it was committed.
+func (self *OutputFile) Discard() {
+	if self.committed {
+		return
+	}
+	self.File.Close()
+	os.Remove(self.Name())
+}
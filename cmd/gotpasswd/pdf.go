@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// CardWriter renders passwords onto printable cards.
+type CardWriter interface {
+	AddCard(label string, passwd string) error
+	Close() error
+}
+
+// PDFCardWriter writes a PDF document with a business card sized page for each password.
+// Only the standard Courier font is used, so characters out of latin1 are rendered as "?".
+type PDFCardWriter struct {
+	w     io.Writer
+	cards []string
+}
+
+const (
+	// 3.5 x 2 inches
+	pdfCardWidth  = 252
+	pdfCardHeight = 144
+)
+
+func NewPDFCardWriter(w io.Writer) *PDFCardWriter {
+	return &PDFCardWriter{w: w}
+}
+
+func (self *PDFCardWriter) AddCard(label string, passwd string) error {
+	var content bytes.Buffer
+	fmt.Fprintf(&content, "BT /F1 10 Tf 18 %d Td (%s) Tj ET\n", pdfCardHeight-30, pdfString(label))
+	fmt.Fprintf(&content, "BT /F1 14 Tf 18 %d Td (%s) Tj ET\n", pdfCardHeight/2-7, pdfString(passwd))
+	self.cards = append(self.cards, content.String())
+	return nil
+}
+
+// Close writes the document, pages are buffered until then to write the cross-reference table.
+func (self *PDFCardWriter) Close() error {
+	var doc bytes.Buffer
+	offsets := make([]int, 0)
+	object := func(body string) {
+		offsets = append(offsets, doc.Len())
+		fmt.Fprintf(&doc, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	doc.WriteString("%PDF-1.4\n")
+	// objects 1-3 are the catalog, the page tree and the font, then a page and its content for each card
+	kids := make([]string, len(self.cards))
+	for i := range self.cards {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+i*2)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(self.cards)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	for i, content := range self.cards {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfCardWidth, pdfCardHeight, 5+i*2))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content))
+	}
+
+	xref := doc.Len()
+	fmt.Fprintf(&doc, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&doc, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&doc, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := self.w.Write(doc.Bytes())
+	return err
+}
+
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0xff:
+			b.WriteByte('?')
+		case r > 0x7e:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
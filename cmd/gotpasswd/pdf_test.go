@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPDFCardWriter(t *testing.T) {
+	var b bytes.Buffer
+	writer := NewPDFCardWriter(&b)
+	if err := writer.AddCard("db", `p4ss(w)rd\1`); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.AddCard("wifi", "café"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	doc := b.String()
+
+	if !strings.HasPrefix(doc, "%PDF-1.4\n") || !strings.HasSuffix(doc, "%%EOF\n") {
+		t.Fatalf("not a PDF document:\n%s", doc)
+	}
+	for _, text := range []string{`(p4ss\(w\)rd\\1) Tj`, `(caf\351) Tj`, "(db) Tj", "/Count 2"} {
+		if !strings.Contains(doc, text) {
+			t.Errorf("%q is not embedded", text)
+		}
+	}
+
+	// offsets of the cross-reference table point at the objects
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(doc)
+	if m == nil {
+		t.Fatal("no startxref")
+	}
+	xref, _ := strconv.Atoi(m[1])
+	if !strings.HasPrefix(doc[xref:], "xref\n") {
+		t.Fatalf("startxref %d does not point at the table", xref)
+	}
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(doc[xref:], -1)
+	if len(offsets) != 7 {
+		t.Fatalf("%d objects, want 7", len(offsets))
+	}
+	for i, offset := range offsets {
+		n, _ := strconv.Atoi(offset[1])
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !strings.HasPrefix(doc[n:], want) {
+			t.Errorf("offset %d of object %d does not point at it", n, i+1)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/kamichidu/go-gotpasswd"
+)
+
+// Policy is a set of rules of passwords, used both to generate passwords and to validate existing ones.
+type Policy struct {
+	MinLength int
+	MaxLength int
+	// Kinds which characters must be of, any if nil
+	Kinds     []gotpasswd.CharacterKind
+	MinKinds  int
+	MinCounts kindCounts
+	Forbidden string
+	// Min entropy in bits, by the length and the pool of kinds of characters appeared
+	MinEntropy float64
+}
+
+// ProfilePolicy returns the policy of rules of the profile, its length is the minimum one.
+// Kinds of the profile are not rules, they are only kinds of generated passwords.
+func ProfilePolicy(profile *gotpasswd.Profile) *Policy {
+	return &Policy{
+		MinLength: profile.Length,
+		MaxLength: profile.MaxLength,
+		MinKinds:  profile.MinKinds,
+		MinCounts: kindCounts(profile.MinCounts),
+	}
+}
+
+// LookupPolicy returns the policy of the profile of the preset name, or reads the policy file of path.
+func LookupPolicy(preset, path string) (*Policy, error) {
+	switch {
+	case preset != "" && path != "":
+		return nil, errors.New("-policy and -policy-preset cannot be used with each other")
+	case preset != "":
+		if profile, ok := gotpasswd.LookupProfile(preset); ok {
+			return ProfilePolicy(profile), nil
+		}
+		return nil, errors.New(fmt.Sprintf("Unknown policy preset: %s", preset))
+	case path != "":
+		return LoadPolicy(path)
+	}
+	return nil, errors.New("-policy or -policy-preset is required")
+}
+
+// LoadPolicy reads a policy, written in the same subset of TOML as the config file:
+//
+//	min-length = 12
+//	max-length = 64
+//	kinds = ["alphabet", "number", "symbol"]
+//	min-kinds = 3
+//	min = ["upper=1", "number=1"]
+//	forbidden = "'\"\\"
+//	min-entropy = 64
+func LoadPolicy(path string) (*Policy, error) {
+	entries, err := readTOMLFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &Policy{MinCounts: make(kindCounts)}
+	for _, entry := range entries {
+		fail := func(format string, args ...interface{}) (*Policy, error) {
+			return nil, errors.New(fmt.Sprintf("%s:%d: %s", path, entry.Line, fmt.Sprintf(format, args...)))
+		}
+		value := strings.Join(entry.Values, ",")
+		switch entry.Key {
+		case "min-length", "max-length", "min-kinds":
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return fail("%s must be positive: %s", entry.Key, value)
+			}
+			switch entry.Key {
+			case "min-length":
+				policy.MinLength = n
+			case "max-length":
+				policy.MaxLength = n
+			default:
+				policy.MinKinds = n
+			}
+		case "kinds":
+			kinds, err := (&gotpasswd.Config{}).ParseKinds(value)
+			if err != nil {
+				return fail("%s", err)
+			}
+			policy.Kinds = kinds
+		case "min":
+			for _, spec := range strings.Split(value, ",") {
+				if err := policy.MinCounts.Set(strings.TrimSpace(spec)); err != nil {
+					return fail("%s", err)
+				}
+			}
+		case "min-entropy":
+			bits, err := strconv.ParseFloat(value, 64)
+			if err != nil || bits <= 0 {
+				return fail("%s must be positive: %s", entry.Key, value)
+			}
+			policy.MinEntropy = bits
+		case "forbidden":
+			if entry.Array {
+				return fail("forbidden must be a string")
+			}
+			policy.Forbidden = value
+		default:
+			return fail("unknown rule: %s", entry.Key)
+		}
+	}
+	if policy.MaxLength > 0 && policy.MaxLength < policy.MinLength {
+		return nil, errors.New(fmt.Sprintf("%s: max-length is shorter than min-length", path))
+	}
+	return policy, nil
+}
+
+// Apply sets constraints of config by the policy, and validates generated passwords by it.
+// Length of config is kept when it's in the range of the policy.
+func (self *Policy) Apply(config *gotpasswd.Config) {
+	if self.Kinds != nil {
+		config.Kinds = self.Kinds
+	}
+	if self.MinLength > 0 && config.Length < self.MinLength {
+		config.Length = self.MinLength
+	}
+	if self.MaxLength > 0 && config.Length > self.MaxLength {
+		config.Length = self.MaxLength
+	}
+	config.AllowedLengths = nil
+	config.MinKinds = max(config.MinKinds, self.MinKinds)
+	if len(self.MinCounts) > 0 {
+		// config may share the map with the profile
+		minCounts := maps.Clone(config.MinCounts)
+		if minCounts == nil {
+			minCounts = make(map[gotpasswd.CharacterKind]int)
+		}
+		for kind, min := range self.MinCounts {
+			minCounts[kind] = max(minCounts[kind], min)
+		}
+		config.MinCounts = minCounts
+	}
+	config.Exclude = append(config.Exclude, []rune(self.Forbidden)...)
+	config.MinEntropy = max(config.MinEntropy, self.MinEntropy)
+	config.Validator = self
+}
+
+// Validate accepts passwords satisfying the policy, as a gotpasswd.Validator.
+func (self *Policy) Validate(ctx context.Context, passwd string) (bool, error) {
+	return len(self.Violations(passwd)) == 0, nil
+}
+
+// Violations returns reasons of rules which passwd violates, one for each rule.
+func (self *Policy) Violations(passwd string) []string {
+	chars := []rune(passwd)
+	reasons := make([]string, 0)
+	if self.MinLength > 0 && len(chars) < self.MinLength {
+		reasons = append(reasons, fmt.Sprintf("shorter than %d characters", self.MinLength))
+	}
+	if self.MaxLength > 0 && len(chars) > self.MaxLength {
+		reasons = append(reasons, fmt.Sprintf("longer than %d characters", self.MaxLength))
+	}
+	if self.Kinds != nil {
+		for _, r := range chars {
+			allowed := false
+			for _, kind := range self.Kinds {
+				allowed = allowed || kind.Contains(r)
+			}
+			if !allowed {
+				reasons = append(reasons, fmt.Sprintf("%q is not of the allowed kinds", r))
+				break
+			}
+		}
+	}
+	if kinds := gotpasswd.KindsOf(passwd); self.MinKinds > 0 && len(kinds) < self.MinKinds {
+		reasons = append(reasons, fmt.Sprintf("%d kinds of characters, at least %d are required", len(kinds), self.MinKinds))
+	}
+	for kind, min := range self.MinCounts {
+		count := 0
+		for _, r := range chars {
+			if kind.Contains(r) {
+				count++
+			}
+		}
+		if count < min {
+			reasons = append(reasons, fmt.Sprintf("%d %s characters, at least %d are required", count, kind, min))
+		}
+	}
+	if i := strings.IndexAny(passwd, self.Forbidden); self.Forbidden != "" && i >= 0 {
+		reasons = append(reasons, fmt.Sprintf("forbidden character %q", []rune(passwd[i:])[0]))
+	}
+	if bits := poolEntropy(passwd); self.MinEntropy > 0 && bits < self.MinEntropy {
+		reasons = append(reasons, fmt.Sprintf("entropy is %.1f bits, lower than %.1f bits", bits, self.MinEntropy))
+	}
+	return reasons
+}
+
+// poolEntropy is the entropy of passwd as if drawn from all characters of the kinds appeared in it,
+// characters of no kinds count by themselves.
+func poolEntropy(passwd string) float64 {
+	pool := 0
+	kinds := gotpasswd.KindsOf(passwd)
+	for _, kind := range kinds {
+		pool += len(gotpasswd.Candidates(&gotpasswd.Config{Kinds: []gotpasswd.CharacterKind{kind}}))
+	}
+	others := make(map[rune]bool)
+	for _, r := range passwd {
+		if _, ok := gotpasswd.KindOf(r); !ok {
+			others[r] = true
+		}
+	}
+	pool += len(others)
+	if pool == 0 {
+		return 0
+	}
+	return float64(len([]rune(passwd))) * math.Log2(float64(pool))
+}
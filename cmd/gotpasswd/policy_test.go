@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kamichidu/go-gotpasswd"
+)
+
+func TestLoadPolicy(t *testing.T) {
+	path := writeTestFile(t, `# policy
+min-length = 12
+kinds = ["alphabet", "number"]
+min = ["upper=1", "number=1"]
+forbidden = "'\"\\" # quotes and backslashes
+`)
+	policy, err := LoadPolicy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.MinLength != 12 {
+		t.Errorf("MinLength = %d, want 12", policy.MinLength)
+	}
+	if !slices.Equal(policy.Kinds, []gotpasswd.CharacterKind{gotpasswd.ALPHABET, gotpasswd.NUMBER}) {
+		t.Errorf("Kinds = %v", policy.Kinds)
+	}
+	if policy.MinCounts[gotpasswd.UPPER] != 1 || policy.MinCounts[gotpasswd.NUMBER] != 1 {
+		t.Errorf("MinCounts = %v", policy.MinCounts)
+	}
+	if policy.Forbidden != `'"\` {
+		t.Errorf("Forbidden = %q", policy.Forbidden)
+	}
+
+	if reasons := policy.Violations("Abcdefghijk12"); len(reasons) != 0 {
+		t.Errorf("Violations = %v, want none", reasons)
+	}
+	// too short, no upper cases nor numbers
+	if reasons := policy.Violations("abcdef"); len(reasons) != 3 {
+		t.Errorf("Violations = %v, want 3 reasons", reasons)
+	}
+}
+
+func TestLoadPolicyErrors(t *testing.T) {
+	for _, content := range []string{
+		"min-length = 0\n",
+		"min-length = 12\nmax-length = 8\n",
+		"unknown = 1\n",
+		"forbidden = ['a']\n",
+		"[policy]\n",
+	} {
+		if _, err := LoadPolicy(writeTestFile(t, content)); err == nil {
+			t.Errorf("LoadPolicy(%q) must fail", content)
+		}
+	}
+}
+
+func TestLookupPolicy(t *testing.T) {
+	policy, err := LookupPolicy("nist", "")
+	if err != nil || policy.MinLength != 15 || policy.MaxLength != 64 {
+		t.Errorf("LookupPolicy(nist) = %+v, %v", policy, err)
+	}
+	// same as the windows-ad profile
+	policy, err = LookupPolicy("ad", "")
+	if err != nil || policy.MinLength != 14 || policy.MinKinds != 3 {
+		t.Errorf("LookupPolicy(ad) = %+v, %v", policy, err)
+	}
+	policy, err = LookupPolicy("pci", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reasons := policy.Violations("abcdefghijkl"); len(reasons) != 1 {
+		t.Errorf("Violations = %v, want one of numbers", reasons)
+	}
+	if _, err := LookupPolicy("unknown", ""); err == nil {
+		t.Error("unknown preset must fail")
+	}
+	if _, err := LookupPolicy("nist", "policy.toml"); err == nil {
+		t.Error("both of preset and file must fail")
+	}
+	if _, err := LookupPolicy("", ""); err == nil {
+		t.Error("neither of preset nor file must fail")
+	}
+}
+
+func TestGenProfiles(t *testing.T) {
+	counts := map[string]int{"nist": 5, "pci": 4, "wifi": 3, "wifi-router": 2, "windows-ad": 6}
+	names := make([]string, 0, len(counts))
+	args := make([]string, 0, len(counts)*2)
+	for _, name := range gotpasswd.ProfileNames() {
+		names = append(names, name)
+		args = append(args, "-gen", fmt.Sprintf("%s=%d", name, counts[name]))
+	}
+	if len(names) != len(counts) {
+		t.Fatalf("profiles %v must be tested", names)
+	}
+	stdout, stderr, status := runMain(t, nil, args...)
+	if status != 0 {
+		t.Fatalf("status = %d, stderr = %q", status, stderr)
+	}
+	generated := make(map[string]int)
+	for _, line := range strings.Split(strings.TrimSuffix(stdout, "\n"), "\n") {
+		name, passwd, ok := strings.Cut(line, "\t")
+		profile, found := gotpasswd.LookupProfile(name)
+		if !ok || !found {
+			t.Fatalf("%q is not labelled by a profile", line)
+		}
+		generated[name]++
+		if reasons := ProfilePolicy(profile).Violations(passwd); len(reasons) > 0 {
+			t.Errorf("%s: %q violates %v", name, passwd, reasons)
+		}
+		config := &gotpasswd.Config{}
+		profile.Apply(config)
+		candidates := string(gotpasswd.Candidates(config))
+		for _, r := range passwd {
+			if !strings.ContainsRune(candidates, r) {
+				t.Errorf("%s: %q contains %q out of the profile", name, passwd, r)
+			}
+		}
+	}
+	if !maps.Equal(generated, counts) {
+		t.Errorf("generated %v, want %v", generated, counts)
+	}
+}
@@ -0,0 +1,502 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"strings"
+)
+
+// qrBlocks is the block structure of a version at the error correction level M.
+type qrBlocks struct {
+	ecPerBlock int
+	// numbers of blocks and their data codewords of the two groups
+	blocks1, data1 int
+	blocks2, data2 int
+}
+
+// Versions 1 to 10 of the level M, up to 213 bytes, which is enough for passwords
+var qrVersions = []qrBlocks{
+	{10, 1, 16, 0, 0},
+	{16, 1, 28, 0, 0},
+	{26, 1, 44, 0, 0},
+	{18, 2, 32, 0, 0},
+	{24, 2, 43, 0, 0},
+	{16, 4, 27, 0, 0},
+	{18, 4, 31, 0, 0},
+	{22, 2, 38, 2, 39},
+	{22, 3, 36, 2, 37},
+	{26, 4, 43, 1, 44},
+}
+
+// Centers of alignment patterns of versions
+var qrAlignments = [][]int{
+	nil,
+	{6, 18},
+	{6, 22},
+	{6, 26},
+	{6, 30},
+	{6, 34},
+	{6, 22, 38},
+	{6, 24, 42},
+	{6, 26, 46},
+	{6, 28, 50},
+}
+
+func (self qrBlocks) dataCodewords() int {
+	return self.blocks1*self.data1 + self.blocks2*self.data2
+}
+
+// QRCode is a matrix of modules, true for dark ones.
+type QRCode struct {
+	Size    int
+	modules [][]bool
+	// function patterns, which are not masked
+	reserved [][]bool
+}
+
+// NewQRCode encodes data in the byte mode at the error correction level M, with the smallest version.
+func NewQRCode(data []byte) (*QRCode, error) {
+	version := 0
+	for v, blocks := range qrVersions {
+		// mode and count indicators, the count is of 16 bits since version 10
+		header := 4 + 8
+		if v+1 >= 10 {
+			header = 4 + 16
+		}
+		if header+len(data)*8 <= blocks.dataCodewords()*8 {
+			version = v + 1
+			break
+		}
+	}
+	if version == 0 {
+		return nil, errors.New(fmt.Sprintf("Too long to encode in a QR code: %d bytes", len(data)))
+	}
+	blocks := qrVersions[version-1]
+
+	var bits qrBits
+	bits.append(0x4, 4)
+	if version >= 10 {
+		bits.append(len(data), 16)
+	} else {
+		bits.append(len(data), 8)
+	}
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	capacity := blocks.dataCodewords() * 8
+	bits.append(0, min(4, capacity-len(bits)))
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0; len(bits) < capacity; pad++ {
+		bits.append([]int{0xEC, 0x11}[pad%2], 8)
+	}
+
+	self := newQRMatrix(version)
+	self.placeCodewords(qrInterleave(bits.bytes(), blocks))
+	self.applyBestMask()
+	return self, nil
+}
+
+type qrBits []bool
+
+func (self *qrBits) append(v int, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*self = append(*self, (v>>i)&1 != 0)
+	}
+}
+
+func (self qrBits) bytes() []byte {
+	b := make([]byte, len(self)/8)
+	for i, bit := range self {
+		if bit {
+			b[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return b
+}
+
+// qrInterleave splits data into blocks, appends Reed-Solomon codewords and interleaves them.
+func qrInterleave(data []byte, blocks qrBlocks) []byte {
+	divisor := rsGenerator(blocks.ecPerBlock)
+	dataBlocks := make([][]byte, 0)
+	ecBlocks := make([][]byte, 0)
+	for i := 0; i < blocks.blocks1+blocks.blocks2; i++ {
+		n := blocks.data1
+		if i >= blocks.blocks1 {
+			n = blocks.data2
+		}
+		dataBlocks = append(dataBlocks, data[:n])
+		ecBlocks = append(ecBlocks, rsRemainder(data[:n], divisor))
+		data = data[n:]
+	}
+	result := make([]byte, 0)
+	for i := 0; i < max(blocks.data1, blocks.data2); i++ {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < blocks.ecPerBlock; i++ {
+		for _, block := range ecBlocks {
+			result = append(result, block[i])
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies in GF(2^8) modulo x^8+x^4+x^3+x^2+1.
+func gfMultiply(x, y byte) byte {
+	var z byte
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x1D)
+		z ^= ((y >> i) & 1) * x
+	}
+	return z
+}
+
+// rsGenerator returns coefficients of the generator polynomial of the degree, except the leading 1.
+func rsGenerator(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	var root byte = 1
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+func rsRemainder(data []byte, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coef := range divisor {
+			result[i] ^= gfMultiply(coef, factor)
+		}
+	}
+	return result
+}
+
+func newQRMatrix(version int) *QRCode {
+	size := version*4 + 17
+	self := &QRCode{Size: size}
+	self.modules = make([][]bool, size)
+	self.reserved = make([][]bool, size)
+	for i := range self.modules {
+		self.modules[i] = make([]bool, size)
+		self.reserved[i] = make([]bool, size)
+	}
+	for i := 0; i < size; i++ {
+		self.setFunction(6, i, i%2 == 0)
+		self.setFunction(i, 6, i%2 == 0)
+	}
+	self.drawFinder(3, 3)
+	self.drawFinder(size-4, 3)
+	self.drawFinder(3, size-4)
+	centers := qrAlignments[version-1]
+	for i, x := range centers {
+		for j, y := range centers {
+			// except ones overlapping finders
+			if (i == 0 && j == 0) || (i == 0 && j == len(centers)-1) || (i == len(centers)-1 && j == 0) {
+				continue
+			}
+			self.drawAlignment(x, y)
+		}
+	}
+	// format areas are reserved here, and drawn with the mask
+	self.drawFormat(0)
+	if version >= 7 {
+		rem := version
+		for i := 0; i < 12; i++ {
+			rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+		}
+		bits := version<<12 | rem
+		for i := 0; i < 18; i++ {
+			dark := (bits>>i)&1 != 0
+			a, b := size-11+i%3, i/3
+			self.setFunction(a, b, dark)
+			self.setFunction(b, a, dark)
+		}
+	}
+	return self
+}
+
+// setFunction sets the module of the column x and the row y as a function pattern.
+func (self *QRCode) setFunction(x, y int, dark bool) {
+	self.modules[y][x] = dark
+	self.reserved[y][x] = true
+}
+
+func (self *QRCode) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			// the outermost ring is the light separator
+			d := max(abs(dx), abs(dy))
+			if xx, yy := x+dx, y+dy; 0 <= xx && xx < self.Size && 0 <= yy && yy < self.Size {
+				self.setFunction(xx, yy, d != 2 && d != 4)
+			}
+		}
+	}
+}
+
+func (self *QRCode) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			self.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// drawFormat draws the level M and the mask, twice.
+func (self *QRCode) drawFormat(mask int) {
+	data := mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool {
+		return (bits>>i)&1 != 0
+	}
+	for i := 0; i <= 5; i++ {
+		self.setFunction(8, i, bit(i))
+	}
+	self.setFunction(8, 7, bit(6))
+	self.setFunction(8, 8, bit(7))
+	self.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		self.setFunction(14-i, 8, bit(i))
+	}
+	for i := 0; i < 8; i++ {
+		self.setFunction(self.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		self.setFunction(8, self.Size-15+i, bit(i))
+	}
+	self.setFunction(8, self.Size-8, true)
+}
+
+// placeCodewords places bits in the zigzag order from the bottom right, skipping the vertical timing pattern.
+func (self *QRCode) placeCodewords(data []byte) {
+	i := 0
+	for right := self.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < self.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = self.Size - 1 - vert
+				}
+				if !self.reserved[y][x] && i < len(data)*8 {
+					self.modules[y][x] = (data[i>>3]>>(7-i&7))&1 != 0
+					i++
+				}
+			}
+		}
+	}
+}
+
+func qrMask(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// applyMask flips data modules by the mask, applying it twice restores them.
+func (self *QRCode) applyMask(mask int) {
+	for y := 0; y < self.Size; y++ {
+		for x := 0; x < self.Size; x++ {
+			if !self.reserved[y][x] && qrMask(mask, x, y) {
+				self.modules[y][x] = !self.modules[y][x]
+			}
+		}
+	}
+}
+
+// applyBestMask applies the mask of the lowest penalty.
+func (self *QRCode) applyBestMask() {
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		self.applyMask(mask)
+		self.drawFormat(mask)
+		if penalty := self.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		self.applyMask(mask)
+	}
+	self.applyMask(best)
+	self.drawFormat(best)
+}
+
+// penalty scores runs, 2x2 blocks, finder-like patterns and the imbalance of dark modules.
+func (self *QRCode) penalty() int {
+	penalty := 0
+	at := func(x, y int, transposed bool) bool {
+		if transposed {
+			return self.modules[x][y]
+		}
+		return self.modules[y][x]
+	}
+	finderLike := []bool{true, false, true, true, true, false, true}
+	for _, transposed := range []bool{false, true} {
+		for y := 0; y < self.Size; y++ {
+			run := 1
+			for x := 1; x <= self.Size; x++ {
+				if x < self.Size && at(x, y, transposed) == at(x-1, y, transposed) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					penalty += 3 + run - 5
+				}
+				run = 1
+			}
+			for x := 0; x+7 <= self.Size; x++ {
+				matched := true
+				for i, dark := range finderLike {
+					matched = matched && at(x+i, y, transposed) == dark
+				}
+				if !matched {
+					continue
+				}
+				light := func(from, to int) bool {
+					for i := from; i < to; i++ {
+						if 0 <= i && i < self.Size && at(i, y, transposed) {
+							return false
+						}
+					}
+					return true
+				}
+				if light(x-4, x) || light(x+7, x+11) {
+					penalty += 40
+				}
+			}
+		}
+	}
+	dark := 0
+	for y := 0; y < self.Size; y++ {
+		for x := 0; x < self.Size; x++ {
+			if self.modules[y][x] {
+				dark++
+			}
+			if x+1 < self.Size && y+1 < self.Size {
+				c := self.modules[y][x]
+				if c == self.modules[y][x+1] && c == self.modules[y+1][x] && c == self.modules[y+1][x+1] {
+					penalty += 3
+				}
+			}
+		}
+	}
+	total := self.Size * self.Size
+	penalty += abs(dark*100/total-50) / 5 * 10
+	return penalty
+}
+
+// Dark reports whether the module of the column x and the row y is dark, out of the code is light.
+func (self *QRCode) Dark(x, y int) bool {
+	return 0 <= x && x < self.Size && 0 <= y && y < self.Size && self.modules[y][x]
+}
+
+// Width of the light margin of QR codes in modules
+const qrQuietZone = 4
+
+// String renders the code by half blocks, two rows of modules per line, in black on white regardless of the terminal theme.
+func (self *QRCode) String() string {
+	return self.Text(true)
+}
+
+// Text renders the code as String, but without escape sequences of colors unless colors is true,
+// for files and terminals without colors, where blocks are dark modules.
+func (self *QRCode) Text(colors bool) string {
+	var b strings.Builder
+	for y := -qrQuietZone; y < self.Size+qrQuietZone; y += 2 {
+		if colors {
+			b.WriteString("\x1b[30;47m")
+		}
+		for x := -qrQuietZone; x < self.Size+qrQuietZone; x++ {
+			top, bottom := self.Dark(x, y), self.Dark(x, y+1)
+			switch {
+			case top && bottom:
+				b.WriteString("█")
+			case top:
+				b.WriteString("▀")
+			case bottom:
+				b.WriteString("▄")
+			default:
+				b.WriteString(" ")
+			}
+		}
+		if colors {
+			b.WriteString("\x1b[0m")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// WritePNG writes the code as a PNG image of the pixels per module.
+func (self *QRCode) WritePNG(w io.Writer, scale int) error {
+	width := (self.Size + qrQuietZone*2) * scale
+	img := image.NewGray(image.Rect(0, 0, width, width))
+	for py := 0; py < width; py++ {
+		for px := 0; px < width; px++ {
+			img.Pix[py*img.Stride+px] = 0xFF
+			if self.Dark(px/scale-qrQuietZone, py/scale-qrQuietZone) {
+				img.Pix[py*img.Stride+px] = 0
+			}
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// Pixels per module of -qr-png
+const qrPNGScale = 8
+
+// writeQRPNG writes the code to the file of 0600, since it's a password.
+func writeQRPNG(path string, code *QRCode) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	err = code.WritePNG(file, qrPNGScale)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
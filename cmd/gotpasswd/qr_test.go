@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestQRCodeText(t *testing.T) {
+	code, err := NewQRCode([]byte("password"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain := code.Text(false)
+	if strings.Contains(plain, "\x1b") {
+		t.Errorf("%q must have no escape sequences", plain)
+	}
+	width := code.Size + qrQuietZone*2
+	lines := strings.Split(strings.TrimSuffix(plain, "\n"), "\n")
+	if len(lines) != (width+1)/2 {
+		t.Errorf("got %d lines, want %d", len(lines), (width+1)/2)
+	}
+	for _, line := range lines {
+		if utf8.RuneCountInString(line) != width {
+			t.Fatalf("%q must be %d modules wide", line, width)
+		}
+	}
+	if colored := code.String(); !strings.HasPrefix(colored, "\x1b[30;47m") || strings.ReplaceAll(strings.ReplaceAll(colored, "\x1b[30;47m", ""), "\x1b[0m", "") != plain {
+		t.Errorf("String must color the text")
+	}
+}
+
+func TestQROutputFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "passwords.txt")
+	if _, stderr, status := runMain(t, nil, "-qr", "-o", path); status != 0 {
+		t.Fatalf("status = %d, stderr = %q", status, stderr)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "\x1b") || !strings.Contains(string(content), "█") {
+		t.Errorf("%q must have the QR code without escape sequences", content)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+)
+
+// Mask of -reveal, of a fixed width not to tell the length
+const revealMask = "************"
+
+// Revealer shows passwords on the terminal masked, reveals each one by Enter and clears it by another Enter.
+// The terminal is used even if stdin and stdout are redirected.
+type Revealer struct {
+	tty    *os.File
+	reader *bufio.Reader
+}
+
+func OpenRevealer() (*Revealer, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &Revealer{tty: tty, reader: bufio.NewReader(tty)}, nil
+}
+
+// wait prints the prompt and waits for Enter, then clears the line including the echoed newline.
+func (self *Revealer) wait(text string) error {
+	fmt.Fprint(self.tty, text)
+	if _, err := self.reader.ReadString('\n'); err != nil {
+		return err
+	}
+	_, err := fmt.Fprint(self.tty, "\x1b[1A\r\x1b[2K")
+	return err
+}
+
+func (self *Revealer) Reveal(label string, line string) error {
+	if label != "" {
+		label += "\t"
+	}
+	if err := self.wait(label + revealMask + "  (Enter to reveal)"); err != nil {
+		return err
+	}
+	if err := self.wait(label + line + "  (Enter to hide)"); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(self.tty, label+revealMask)
+	return err
+}
+
+func (self *Revealer) Close() error {
+	return self.tty.Close()
+}
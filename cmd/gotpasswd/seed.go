@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"strings"
+)
+
+// NewSeededReader returns a deterministic byte stream derived from the seed.
+// Anyone who knows the seed can reproduce the passwords, so they're not secret.
+func NewSeededReader(seed string) io.Reader {
+	return &hmacStream{
+		secret: []byte(seed),
+	}
+}
+
+// ShareCommand returns a command line reproducing the run, built from flags differ from their defaults.
+func ShareCommand(flags *flag.FlagSet) string {
+	args := []string{"gotpasswd"}
+	flags.VisitAll(func(f *flag.Flag) {
+		if _, ok := flagAliases[f.Name]; ok || f.Name == "share" || f.Name == "debug" {
+			return
+		}
+		if f.Value.String() == f.DefValue {
+			return
+		}
+		if getter, ok := f.Value.(flag.Getter); ok {
+			if v, ok := getter.Get().(bool); ok && v {
+				args = append(args, "-"+f.Name)
+				return
+			}
+		}
+		if repeatable, ok := f.Value.(repeatableValue); ok {
+			for _, value := range repeatable.Values() {
+				args = append(args, "-"+f.Name+"="+shellQuote(value))
+			}
+			return
+		}
+		args = append(args, "-"+f.Name+"="+shellQuote(f.Value.String()))
+	})
+	return strings.Join(args, " ")
+}
+
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_.,:/=@%+", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/kamichidu/go-gotpasswd"
+)
+
+type shareTestFlags struct {
+	flags     *flag.FlagSet
+	kinds     *string
+	length    *int
+	seed      *string
+	minCounts kindCounts
+}
+
+func newShareTestFlags() *shareTestFlags {
+	self := &shareTestFlags{flags: flag.NewFlagSet("gotpasswd", flag.ContinueOnError), minCounts: kindCounts{}}
+	self.kinds = self.flags.String("k", "alphabet", "")
+	self.length = self.flags.Int("l", 8, "")
+	self.seed = self.flags.String("seed", "", "")
+	self.flags.String("template", "", "")
+	self.flags.Bool("no-ambiguous", false, "")
+	self.flags.Bool("share", false, "")
+	self.flags.Var(self.minCounts, "min", "")
+	self.flags.Var(&genSpecs{}, "gen", "")
+	return self
+}
+
+func (self *shareTestFlags) generate(t *testing.T) string {
+	config := &gotpasswd.Config{Length: *self.length, MinCounts: self.minCounts}
+	kinds, err := config.ParseKinds(*self.kinds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Kinds = kinds
+	passwds := make([]string, 3)
+	generator := gotpasswd.NewGenerator(NewSeededReader(*self.seed))
+	for i := range passwds {
+		passwd, err := generator.GeneratePassword(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		passwds[i] = passwd.Value
+	}
+	return strings.Join(passwds, "\n")
+}
+
+// shellSplit splits words of a command line quoted by shellQuote.
+func shellSplit(s string) []string {
+	words := make([]string, 0)
+	var word strings.Builder
+	quoted, escaped, inWord := false, false, false
+	for _, r := range s {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\'':
+			quoted = !quoted
+			inWord = true
+		case r == ' ' && !quoted:
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+			}
+			inWord = false
+		case r == '\\' && !quoted:
+			escaped = true
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+func TestShareCommand(t *testing.T) {
+	original := newShareTestFlags()
+	err := original.flags.Parse([]string{
+		"-k", "upper,number", "-l", "20", "-seed", "bug report",
+		"-min", "upper=2", "-min", "number=3", "-gen", "nist=1", "-gen", "wifi=2",
+		"-template", "{{.Password}} isn't secret", "-no-ambiguous", "-share",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	command := ShareCommand(original.flags)
+	args := shellSplit(command)
+	if args[0] != "gotpasswd" {
+		t.Fatalf("%s is not a command line of gotpasswd", command)
+	}
+
+	shared := newShareTestFlags()
+	if err := shared.flags.Parse(args[1:]); err != nil {
+		t.Fatalf("%s: %s", command, err)
+	}
+	if shared.flags.NArg() != 0 {
+		t.Errorf("%s: %q are left", command, shared.flags.Args())
+	}
+	original.flags.VisitAll(func(f *flag.Flag) {
+		if f.Name == "share" {
+			return
+		}
+		if value := shared.flags.Lookup(f.Name).Value.String(); value != f.Value.String() {
+			t.Errorf("-%s is %q, want %q", f.Name, value, f.Value.String())
+		}
+	})
+	if other := ShareCommand(shared.flags); other != command {
+		t.Errorf("%s differs from %s", other, command)
+	}
+	if passwds, want := shared.generate(t), original.generate(t); passwds != want {
+		t.Errorf("passwords are %q, want %q", passwds, want)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kamichidu/go-gotpasswd"
+)
+
+// Max number of passwords of a request
+const maxServeNum = 1000
+
+// Serve responds passwords generated with config as JSON lines of -format jsonl, until ctx is done.
+func Serve(ctx context.Context, addr string, generator *gotpasswd.Generator, config *gotpasswd.Config) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           newServeHandler(generator, config),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      time.Minute,
+		IdleTimeout:       time.Minute,
+	}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func newServeHandler(generator *gotpasswd.Generator, config *gotpasswd.Config) http.Handler {
+	// streams of -seed, -time-bucket and -derive-salt, and validators such as -history, are not safe for concurrent use
+	var mu sync.Mutex
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		num := 1
+		if s := r.URL.Query().Get("n"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n <= 0 || n > maxServeNum {
+				http.Error(w, fmt.Sprintf("n must be from 1 to %d", maxServeNum), http.StatusBadRequest)
+				return
+			}
+			num = n
+		}
+		passwords := make([]gotpasswd.Password, 0, num)
+		mu.Lock()
+		for i := 0; i < num; i++ {
+			passwd, err := generator.GeneratePasswordContext(r.Context(), config)
+			if err != nil {
+				mu.Unlock()
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			passwords = append(passwords, passwd)
+		}
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Cache-Control", "no-store")
+		output := NewJSONLinesWriter(w)
+		for i, passwd := range passwords {
+			if err := output.Write(i+1, "", passwd); err != nil {
+				return
+			}
+		}
+	})
+}
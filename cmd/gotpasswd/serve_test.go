@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/kamichidu/go-gotpasswd"
+)
+
+// Run with -race, handlers share the seeded stream
+func TestServeHandler(t *testing.T) {
+	config := &gotpasswd.Config{Kinds: []gotpasswd.CharacterKind{gotpasswd.ALPHABET, gotpasswd.NUMBER}, Length: 16}
+	server := httptest.NewServer(newServeHandler(gotpasswd.NewGenerator(NewSeededReader("seed")), config))
+	defer server.Close()
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		seen = make(map[string]bool)
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := http.Get(server.URL + "/?n=500")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("status = %d", resp.StatusCode)
+				return
+			}
+			scanner := bufio.NewScanner(resp.Body)
+			for scanner.Scan() {
+				var record passwordRecord
+				if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				seen[record.Password] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	// each password is drawn from the stream once
+	if len(seen) != 8*500 {
+		t.Errorf("got %d distinct passwords, want %d", len(seen), 8*500)
+	}
+
+	for _, c := range []struct {
+		method, query string
+		status        int
+	}{
+		{http.MethodPost, "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "?n=0", http.StatusBadRequest},
+		{http.MethodGet, "?n=1001", http.StatusBadRequest},
+	} {
+		req, err := http.NewRequest(c.method, server.URL+"/"+c.query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != c.status {
+			t.Errorf("%s %s = %d, want %d", c.method, c.query, resp.StatusCode, c.status)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/kamichidu/go-gotpasswd"
+)
+
+var templateVarPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// TemplateWriter writes each password by a text/template, terminated by Terminator.
+// Fields are Index, Label, Password, Length, Kinds, Entropy and CrackEstimate, and vars whose first letter is capitalized,
+// such as .User for the var user.
+type TemplateWriter struct {
+	Terminator string
+
+	w        io.Writer
+	template *template.Template
+	vars     map[string]interface{}
+}
+
+func NewTemplateWriter(w io.Writer, text string, vars map[string]string) (*TemplateWriter, error) {
+	tmpl, err := template.New("template").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	self := &TemplateWriter{
+		Terminator: "\n",
+		w:          w,
+		template:   tmpl,
+		vars:       make(map[string]interface{}),
+	}
+	builtins := self.data(&passwordRecord{})
+	for name, value := range vars {
+		if !templateVarPattern.MatchString(name) {
+			return nil, errors.New(fmt.Sprintf("Invalid var name, must be letters, digits and underscores: %s", name))
+		}
+		field := strings.ToUpper(name[:1]) + name[1:]
+		if _, ok := builtins[field]; ok {
+			return nil, errors.New(fmt.Sprintf("Var %s conflicts with the field .%s", name, field))
+		}
+		self.vars[field] = value
+	}
+	return self, nil
+}
+
+func (self *TemplateWriter) data(record *passwordRecord) map[string]interface{} {
+	data := map[string]interface{}{
+		"Index":    record.Index,
+		"Label":    record.Label,
+		"Password": record.Password,
+		"Length":   record.Length,
+		"Kinds":    record.Kinds,
+		"Entropy":  record.EntropyBits,
+		// nil without -crack-estimate
+		"CrackEstimate": record.CrackEstimate,
+	}
+	for name, value := range self.vars {
+		data[name] = value
+	}
+	return data
+}
+
+func (self *TemplateWriter) Write(index int, label string, passwd gotpasswd.Password) error {
+	if err := self.template.Execute(self.w, self.data(newPasswordRecord(index, label, passwd))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(self.w, self.Terminator)
+	return err
+}
+
+func (self *TemplateWriter) Close() error {
+	return nil
+}
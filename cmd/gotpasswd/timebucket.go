@@ -0,0 +1,46 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// NewTimeBucketReader returns a deterministic byte stream derived from HMAC-SHA256(secret, bucket),
+// where bucket is the index of the time window which now belongs to.
+// Passwords generated from it are reproducible within a window and change across windows.
+// This is NOT a TOTP (RFC 6238), use it only for test environments.
+func NewTimeBucketReader(secret []byte, window time.Duration, now time.Time) io.Reader {
+	return &hmacStream{
+		secret: secret,
+		bucket: uint64(now.UnixNano() / int64(window)),
+	}
+}
+
+type hmacStream struct {
+	secret  []byte
+	bucket  uint64
+	counter uint64
+	buf     []byte
+}
+
+func (self *hmacStream) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(self.buf) == 0 {
+			var msg [16]byte
+			binary.BigEndian.PutUint64(msg[:8], self.bucket)
+			binary.BigEndian.PutUint64(msg[8:], self.counter)
+			self.counter++
+			mac := hmac.New(sha256.New, self.secret)
+			mac.Write(msg[:])
+			self.buf = mac.Sum(nil)
+		}
+		copied := copy(p[n:], self.buf)
+		self.buf = self.buf[copied:]
+		n += copied
+	}
+	return n, nil
+}
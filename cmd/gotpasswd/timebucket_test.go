@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kamichidu/go-gotpasswd"
+)
+
+func TestTimeBucketReader(t *testing.T) {
+	config := &gotpasswd.Config{Kinds: []gotpasswd.CharacterKind{gotpasswd.ALPHABET, gotpasswd.NUMBER}, Length: 16}
+	generate := func(secret string, now time.Time) string {
+		passwd, err := gotpasswd.NewGenerator(NewTimeBucketReader([]byte(secret), time.Hour, now)).GeneratePassword(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return passwd.Value
+	}
+	start := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	passwd := generate("key", start)
+	if other := generate("key", start.Add(59*time.Minute)); other != passwd {
+		t.Errorf("%q and %q differ in the same window", passwd, other)
+	}
+	if other := generate("key", start.Add(time.Hour)); other == passwd {
+		t.Errorf("%q is same in the next window", passwd)
+	}
+	if other := generate("other", start); other == passwd {
+		t.Errorf("%q is same for another secret", passwd)
+	}
+}
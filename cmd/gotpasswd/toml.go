@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// tomlEntry is a key = value line of a TOML file.
+type tomlEntry struct {
+	Line   int
+	Key    string
+	Values []string
+	// Whether the value is an array
+	Array bool
+}
+
+// readTOMLFile reads entries of a subset of TOML: strings, numbers, booleans and single line arrays of them,
+// without tables. Values are returned as strings, which are unquoted.
+func readTOMLFile(path string) ([]tomlEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries := make([]tomlEntry, 0)
+	scanner := bufio.NewScanner(file)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") || line == "" {
+			continue
+		}
+		fail := func(format string, args ...interface{}) ([]tomlEntry, error) {
+			return nil, errors.New(fmt.Sprintf("%s:%d: %s", path, lineno, fmt.Sprintf(format, args...)))
+		}
+		if strings.HasPrefix(line, "[") {
+			return fail("tables are not supported")
+		}
+		pair := strings.SplitN(line, "=", 2)
+		if len(pair) != 2 {
+			return fail("must be key = value")
+		}
+		key := strings.TrimSpace(pair[0])
+		if unquoted, err := strconv.Unquote(key); err == nil {
+			key = unquoted
+		}
+		values, array, err := parseTOMLValue(strings.TrimSpace(pair[1]))
+		if err != nil {
+			return fail("%s", err)
+		}
+		entries = append(entries, tomlEntry{Line: lineno, Key: key, Values: values, Array: array})
+	}
+	return entries, scanner.Err()
+}
+
+// parseTOMLValue parses a string, number, boolean or an array of them in a line, followed by an optional comment.
+func parseTOMLValue(s string) (values []string, array bool, err error) {
+	rest := s
+	if strings.HasPrefix(rest, "[") {
+		array = true
+		rest = strings.TrimSpace(rest[1:])
+		for !strings.HasPrefix(rest, "]") {
+			var value string
+			if value, rest, err = parseTOMLScalar(rest); err != nil {
+				return nil, false, err
+			}
+			values = append(values, value)
+			rest = strings.TrimSpace(rest)
+			if strings.HasPrefix(rest, ",") {
+				rest = strings.TrimSpace(rest[1:])
+			} else if !strings.HasPrefix(rest, "]") {
+				return nil, false, errors.New("array must be closed by ] on the same line")
+			}
+		}
+		rest = rest[1:]
+	} else {
+		var value string
+		if value, rest, err = parseTOMLScalar(rest); err != nil {
+			return nil, false, err
+		}
+		values = []string{value}
+	}
+	if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+		return nil, false, errors.New(fmt.Sprintf("unexpected %s after the value", rest))
+	}
+	return values, array, nil
+}
+
+func parseTOMLScalar(s string) (value, rest string, err error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		// escapes of basic strings are a subset of Go's
+		for i := 1; i < len(s); i++ {
+			if s[i] == '\\' {
+				i++
+			} else if s[i] == '"' {
+				value, err = strconv.Unquote(s[:i+1])
+				return value, s[i+1:], err
+			}
+		}
+		return "", "", errors.New("string must be closed by \"")
+	case strings.HasPrefix(s, "'"):
+		i := strings.Index(s[1:], "'")
+		if i < 0 {
+			return "", "", errors.New("string must be closed by '")
+		}
+		return s[1 : i+1], s[i+2:], nil
+	}
+	end := strings.IndexAny(s, ",]# \t")
+	if end < 0 {
+		end = len(s)
+	}
+	value = s[:end]
+	if value == "true" || value == "false" {
+		return value, s[end:], nil
+	}
+	if _, err := strconv.ParseFloat(strings.ReplaceAll(value, "_", ""), 64); err != nil {
+		return "", "", errors.New(fmt.Sprintf("invalid value: %s, strings must be quoted", value))
+	}
+	return strings.ReplaceAll(value, "_", ""), s[end:], nil
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/kamichidu/go-gotpasswd"
+)
+
+// Kinds toggled by number keys of the TUI
+var tuiKinds = []gotpasswd.CharacterKind{
+	gotpasswd.UPPER,
+	gotpasswd.LOWER,
+	gotpasswd.NUMBER,
+	gotpasswd.SYMBOL,
+	gotpasswd.UNDERSCORE,
+	gotpasswd.SPACE,
+}
+
+const (
+	tuiMaxLength = 128
+	tuiMaxWords  = 24
+)
+
+// TUI is the interactive generator of "gotpasswd tui".
+type TUI struct {
+	tty       *os.File
+	reader    *bufio.Reader
+	generator *gotpasswd.Generator
+
+	enabled    map[gotpasswd.CharacterKind]bool
+	length     int
+	words      int
+	passphrase bool
+	passwd     gotpasswd.Password
+	message    string
+}
+
+func NewTUI(generator *gotpasswd.Generator) *TUI {
+	return &TUI{
+		generator: generator,
+		enabled: map[gotpasswd.CharacterKind]bool{
+			gotpasswd.UPPER:  true,
+			gotpasswd.LOWER:  true,
+			gotpasswd.NUMBER: true,
+		},
+		length: 16,
+		words:  5,
+	}
+}
+
+func (self *TUI) config() *gotpasswd.Config {
+	config := &gotpasswd.Config{Length: self.length, Num: 1}
+	if self.passphrase {
+		config.Words = self.words
+	}
+	for _, kind := range tuiKinds {
+		if self.enabled[kind] {
+			config.Kinds = append(config.Kinds, kind)
+		}
+	}
+	return config
+}
+
+func (self *TUI) regenerate() {
+	config := self.config()
+	if err := config.Validate(); err != nil {
+		self.passwd = gotpasswd.Password{}
+		self.message = err.Error()
+		return
+	}
+	passwd, err := self.generator.GeneratePassword(config)
+	if err != nil {
+		self.message = err.Error()
+		return
+	}
+	self.passwd = passwd
+}
+
+// stty runs stty on the terminal, raw mode needs it without terminal APIs of the standard library.
+func (self *TUI) stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = self.tty
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// Run shows the TUI on the terminal until q or Ctrl-C.
+func (self *TUI) Run() error {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer tty.Close()
+	self.tty = tty
+	self.reader = bufio.NewReader(tty)
+
+	state, err := self.stty("-g")
+	if err != nil {
+		return err
+	}
+	if _, err := self.stty("raw", "-echo"); err != nil {
+		return err
+	}
+	defer func() {
+		self.stty(state)
+		// the password is left out of scrollback
+		fmt.Fprint(self.tty, "\x1b[H\x1b[2J")
+	}()
+
+	self.regenerate()
+	for {
+		self.render()
+		key, err := self.readKey()
+		if err != nil {
+			return err
+		}
+		self.message = ""
+		switch key {
+		case "q", "\x03":
+			return nil
+		case "up", "right":
+			if self.passphrase {
+				self.words = min(self.words+1, tuiMaxWords)
+			} else {
+				self.length = min(self.length+1, tuiMaxLength)
+			}
+			self.regenerate()
+		case "down", "left":
+			if self.passphrase {
+				self.words = max(self.words-1, 1)
+			} else {
+				self.length = max(self.length-1, 1)
+			}
+			self.regenerate()
+		case "w":
+			self.passphrase = !self.passphrase
+			self.regenerate()
+		case "r", "\r":
+			self.regenerate()
+		case "c":
+			if self.passwd.Value == "" {
+				break
+			}
+			if err := CopyToClipboard(self.passwd.Value, defaultCopySeconds); err != nil {
+				self.message = err.Error()
+			} else {
+				self.message = fmt.Sprintf("Copied, it will be cleared in %d seconds", defaultCopySeconds)
+			}
+		default:
+			for i, kind := range tuiKinds {
+				if key == fmt.Sprint(i+1) {
+					self.enabled[kind] = !self.enabled[kind]
+					self.regenerate()
+				}
+			}
+		}
+	}
+}
+
+// readKey reads a key, arrow keys are named by their directions.
+func (self *TUI) readKey() (string, error) {
+	b, err := self.reader.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	if b != 0x1b || self.reader.Buffered() < 2 {
+		return string(b), nil
+	}
+	seq := make([]byte, 2)
+	if _, err := self.reader.Read(seq); err != nil {
+		return "", err
+	}
+	switch string(seq) {
+	case "[A":
+		return "up", nil
+	case "[B":
+		return "down", nil
+	case "[C":
+		return "right", nil
+	case "[D":
+		return "left", nil
+	}
+	return "", nil
+}
+
+func (self *TUI) render() {
+	lines := make([]string, 0)
+	lines = append(lines, "gotpasswd", "")
+	if self.passwd.Value != "" {
+		lines = append(lines, "  "+Colorize(self.passwd.Value), "")
+		lines = append(lines, fmt.Sprintf("  %.1f bits", self.passwd.Entropy))
+	} else {
+		lines = append(lines, "  (none)", "", "")
+	}
+	if self.passphrase {
+		lines = append(lines, fmt.Sprintf("  passphrase of %d words (up/down)", self.words))
+	} else {
+		lines = append(lines, fmt.Sprintf("  password of %d characters (up/down)", self.length))
+	}
+	lines = append(lines, "")
+	for i, kind := range tuiKinds {
+		mark := " "
+		if self.enabled[kind] {
+			mark = "x"
+		}
+		lines = append(lines, fmt.Sprintf("  %d [%s] %s", i+1, mark, kind))
+	}
+	lines = append(lines, "", "  w: password/passphrase  r: regenerate  c: copy  q: quit")
+	if self.message != "" {
+		lines = append(lines, "", "  "+self.message)
+	}
+	// raw mode doesn't translate newlines
+	fmt.Fprint(self.tty, "\x1b[H\x1b[2J"+strings.Join(lines, "\r\n")+"\r\n")
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/kamichidu/go-gotpasswd"
+)
+
+// Max regenerations of a password which was generated before, with -unique
+const maxUniqueRetries = 1000
+
+// checkKeyspace reports an error if num passwords cannot be distinct under config,
+// the number of possible passwords is estimated by the entropy.
+func checkKeyspace(config *gotpasswd.Config, num int) error {
+	entropy := gotpasswd.Entropy(config)
+	// tolerance of rounding errors, such as 2^log2(100)
+	if math.Log2(float64(num)) > entropy+1e-9 {
+		return errors.New(fmt.Sprintf("Cannot generate %d unique passwords, only about %.0f passwords are possible with %.1f bits", num, math.Exp2(entropy), entropy))
+	}
+	return nil
+}
+
+// generateUnique generates a password not in seen, and adds it to seen.
+func generateUnique(ctx context.Context, generator *gotpasswd.Generator, config *gotpasswd.Config, seen map[string]bool) (gotpasswd.Password, error) {
+	for retry := 0; retry < maxUniqueRetries; retry++ {
+		passwd, err := generator.GeneratePasswordContext(ctx, config)
+		if err != nil || !seen[passwd.Value] {
+			seen[passwd.Value] = true
+			return passwd, err
+		}
+	}
+	return gotpasswd.Password{}, errors.New(fmt.Sprintf("Cannot generate a unique password in %d retries, %d passwords were generated", maxUniqueRetries, len(seen)))
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	runtimedebug "runtime/debug"
+)
+
+// Build metadata set by -ldflags, such as
+//
+//	go build -ldflags "-X main.buildVersion=v1.0.0 -X main.buildCommit=$(git rev-parse HEAD) -X main.buildDate=$(date -u +%FT%TZ)"
+//
+// and filled by the build info of the binary if empty.
+var (
+	buildVersion string
+	buildCommit  string
+	buildDate    string
+)
+
+// VersionInfo describes the build of the binary.
+type VersionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	Modified  bool   `json:"modified"`
+	GoVersion string `json:"go_version"`
+}
+
+func currentVersion() *VersionInfo {
+	info := &VersionInfo{
+		Version:   buildVersion,
+		Commit:    buildCommit,
+		Date:      buildDate,
+		GoVersion: runtime.Version(),
+	}
+	if build, ok := runtimedebug.ReadBuildInfo(); ok {
+		if info.Version == "" && build.Main.Version != "" {
+			info.Version = build.Main.Version
+		}
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.Date == "" {
+					info.Date = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+	if info.Version == "" {
+		info.Version = "(devel)"
+	}
+	return info
+}
+
+// WriteVersion writes the build metadata in the format, text or json.
+func WriteVersion(w io.Writer, format string) error {
+	info := currentVersion()
+	switch format {
+	case "text":
+		fmt.Fprintf(w, "gotpasswd %s\n", info.Version)
+		commit := info.Commit
+		if commit == "" {
+			commit = "unknown"
+		} else if info.Modified {
+			commit += " (modified)"
+		}
+		date := info.Date
+		if date == "" {
+			date = "unknown"
+		}
+		fmt.Fprintf(w, "commit: %s\nbuilt: %s\ngo: %s\n", commit, date, info.GoVersion)
+		return nil
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(info)
+	default:
+		return errors.New(fmt.Sprintf("Unknown format of -version: %s, one of text and json", format))
+	}
+}
@@ -0,0 +1,280 @@
+package gotpasswd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Satisfies reports whether chars satisfies all constraints of config.
+func Satisfies(config *Config, chars []rune) bool {
+	return violation(config, chars) == ""
+}
+
+// violation describes the first constraint which chars violates, or returns empty.
+func violation(config *Config, chars []rune) string {
+	// characters not from Candidates, such as Grammar and Positions, are checked here
+	if config.TargetEncoding != "" && !encodable(chars, config.TargetEncoding) {
+		return "not encodable in " + config.TargetEncoding
+	}
+	if len(config.Exclude) > 0 && strings.ContainsAny(string(chars), string(config.Exclude)) {
+		return "excluded characters"
+	}
+	if config.SQLSafe != "" && strings.ContainsAny(string(chars), sqlDialects[config.SQLSafe]) {
+		return "characters needing escapes in SQL"
+	}
+	for _, context := range config.SafeFor {
+		for _, r := range chars {
+			if !safeFor(context, r) {
+				return "characters needing escapes in " + context
+			}
+		}
+	}
+	if config.NoRepeatedBigrams && hasRepeatedBigram(chars) {
+		return "repeated bigrams"
+	}
+	if len(chars) > 0 && len(config.StartWith) > 0 && !containsAnyKind(config.StartWith, chars[0]) {
+		return "the first character of other kinds"
+	}
+	if len(chars) > 0 && len(config.EndWith) > 0 && !containsAnyKind(config.EndWith, chars[len(chars)-1]) {
+		return "the last character of other kinds"
+	}
+	if config.NoRepeat && hasRepeat(chars) {
+		return "repeated characters"
+	}
+	if config.NoAdjacentRepeat && hasAdjacentRepeat(chars) {
+		return "adjacent repeated characters"
+	}
+	if config.MinKinds > 0 && len(KindsOf(string(chars))) < config.MinKinds {
+		return "too few character kinds"
+	}
+	if config.MinTransitions > 0 && transitions(chars) < config.MinTransitions {
+		return "too few character kind transitions"
+	}
+	if config.MinCaseChanges > 0 && caseChanges(chars) < config.MinCaseChanges {
+		return "too few case changes"
+	}
+	// flagged words across passphrase words are detected by removing separators
+	if len(config.Blocklist) > 0 {
+		joined := string(chars)
+		if separator := config.separator(); separator != "" {
+			joined = strings.ReplaceAll(joined, separator, "")
+		}
+		if containsBlocked(joined, config.Blocklist) {
+			return "flagged words"
+		}
+	}
+	if config.KindWindow != nil && !config.KindWindow.Satisfied(chars) {
+		return "too many characters of a kind in a window"
+	}
+	if config.MaxDistinctSymbols > 0 && distinctSymbols(chars) > config.MaxDistinctSymbols {
+		return "too many distinct symbols"
+	}
+	if config.RequireAllKinds {
+		for _, kind := range config.Kinds {
+			if countKind(chars, kind) == 0 {
+				return fmt.Sprintf("no %s characters", kind)
+			}
+		}
+	}
+	for kind, min := range config.MinCounts {
+		if countKind(chars, kind) < min {
+			return fmt.Sprintf("too few %s characters", kind)
+		}
+	}
+	for kind, max := range config.MaxCounts {
+		if countKind(chars, kind) > max {
+			return fmt.Sprintf("too many %s characters", kind)
+		}
+	}
+	if config.MinShannon > 0 && shannon(chars) < config.MinShannon {
+		return "too low Shannon entropy"
+	}
+	if config.NoLeetWords && leetWord(string(chars)) {
+		return "a dictionary word disguised by l33t substitutions"
+	}
+	if config.NoDictWords {
+		words := config.DictWords
+		if words == nil {
+			words = append(append([]string{}, weakWords...), wordlist...)
+		}
+		if word := dictWord(chars, words); word != "" {
+			return "the dictionary word " + word
+		}
+	}
+	if config.MaxWalk > 0 && longestWalk(chars) > config.MaxWalk {
+		return "a keyboard walk or sequential characters"
+	}
+	if config.NoTrivialPins {
+		if reason := trivialPin(chars); reason != "" {
+			return "a trivial PIN of " + reason
+		}
+	}
+	for _, group := range config.RequireOneOf {
+		if !strings.ContainsAny(string(chars), string(group)) {
+			return fmt.Sprintf("none of %q", string(group))
+		}
+	}
+	for _, pattern := range config.Patterns {
+		if !pattern.MatchString(string(chars)) {
+			return fmt.Sprintf("not matched to pattern %s", pattern)
+		}
+	}
+	return ""
+}
+
+// caseChanges counts changes between lower and upper case among letters, ignoring other characters.
+func caseChanges(chars []rune) int {
+	count := 0
+	var prev rune
+	for _, r := range chars {
+		if !unicode.IsUpper(r) && !unicode.IsLower(r) {
+			continue
+		}
+		if prev != 0 && unicode.IsUpper(prev) != unicode.IsUpper(r) {
+			count++
+		}
+		prev = r
+	}
+	return count
+}
+
+// shannon computes the empirical Shannon entropy in bits per character of the character distribution in chars.
+// Unlike Entropy, which is the theoretical entropy of the generation from the pool, it measures the drawn characters,
+// so it is at most log2 of the length and degenerate outputs such as "aaaa" have 0.
+func shannon(chars []rune) float64 {
+	counts := make(map[rune]int)
+	for _, r := range chars {
+		counts[r]++
+	}
+	bits := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(len(chars))
+		bits -= p * math.Log2(p)
+	}
+	return bits
+}
+
+// countKind counts characters of the kind in chars.
+func countKind(chars []rune, kind CharacterKind) int {
+	count := 0
+	for _, r := range chars {
+		if kindContains(kind, r) {
+			count++
+		}
+	}
+	return count
+}
+
+func distinctSymbols(chars []rune) int {
+	seen := make(map[rune]bool)
+	for _, r := range chars {
+		if kind, ok := KindOf(r); ok && kind == SYMBOL {
+			seen[r] = true
+		}
+	}
+	return len(seen)
+}
+
+func isSymbol(c rune) bool {
+	kind, _ := KindOf(c)
+	return kind == SYMBOL
+}
+
+// limitedSymbolsCount returns the number of characters left in charCandidates by limitSymbols of n symbols.
+func limitedSymbolsCount(charCandidates []rune, n int) int {
+	symbols := len(filterRunes(charCandidates, isSymbol))
+	if n <= 0 || n >= symbols {
+		return len(charCandidates)
+	}
+	return len(charCandidates) - symbols + n
+}
+
+// symbolChoiceEntropy returns the entropy in bits of the choice of n symbols by limitSymbols, log2 C(symbols, n).
+func symbolChoiceEntropy(charCandidates []rune, n int) float64 {
+	symbols := len(filterRunes(charCandidates, isSymbol))
+	if n <= 0 || n >= symbols {
+		return 0
+	}
+	lgamma := func(x int) float64 {
+		v, _ := math.Lgamma(float64(x + 1))
+		return v
+	}
+	return (lgamma(symbols) - lgamma(n) - lgamma(symbols-n)) / math.Ln2
+}
+
+// limitSymbols chooses n symbols randomly, and removes other symbols from charCandidates.
+func limitSymbols(r io.Reader, charCandidates []rune, n int) ([]rune, error) {
+	symbols := filterRunes(charCandidates, isSymbol)
+	// partial Fisher-Yates shuffle
+	for i := 0; i < n && i < len(symbols); i++ {
+		j, err := randomInt(r, len(symbols)-i)
+		if err != nil {
+			return nil, err
+		}
+		symbols[i], symbols[i+j] = symbols[i+j], symbols[i]
+	}
+	if n < len(symbols) {
+		symbols = symbols[:n]
+	}
+	return filterRunes(charCandidates, func(c rune) bool {
+		kind, _ := KindOf(c)
+		return kind != SYMBOL || strings.ContainsRune(string(symbols), c)
+	}), nil
+}
+
+// transitions counts changes of character kind between adjacent characters.
+func transitions(chars []rune) int {
+	kindOf := func(r rune) CharacterKind {
+		if kind, ok := KindOf(r); ok {
+			return kind
+		}
+		// characters out of the dictionary are treated as another kind
+		return -1
+	}
+	count := 0
+	for i := 0; i+1 < len(chars); i++ {
+		if kindOf(chars[i]) != kindOf(chars[i+1]) {
+			count++
+		}
+	}
+	return count
+}
+
+func hasRepeatedBigram(chars []rune) bool {
+	seen := make(map[[2]rune]bool)
+	for i := 0; i+1 < len(chars); i++ {
+		bigram := [2]rune{chars[i], chars[i+1]}
+		if seen[bigram] {
+			return true
+		}
+		seen[bigram] = true
+	}
+	return false
+}
+
+// LoadPatterns reads regular expressions from the file, one per line.
+func LoadPatterns(path string) ([]*regexp.Regexp, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	patterns := make([]*regexp.Regexp, 0)
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
+		}
+		pattern, err := regexp.Compile(line)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("%s:%d: %s", path, i+1, err))
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
@@ -0,0 +1,179 @@
+package gotpasswd
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMaxDistinctSymbols(t *testing.T) {
+	config := &Config{
+		Kinds:              []CharacterKind{ALPHABET, NUMBER, SYMBOL},
+		Length:             32,
+		MaxDistinctSymbols: 2,
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		passwd, err := Generate(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := distinctSymbols([]rune(passwd)); n > 2 {
+			t.Fatalf("%q uses %d distinct symbols", passwd, n)
+		}
+	}
+}
+
+func TestMaxDistinctSymbolsEntropy(t *testing.T) {
+	config := &Config{Kinds: []CharacterKind{SYMBOL}, Length: 10, MaxDistinctSymbols: 1}
+	symbols := len(Candidates(config))
+	// only the choice of a symbol is random
+	if entropy, expected := Entropy(config), math.Log2(float64(symbols)); math.Abs(entropy-expected) > 1e-9 {
+		t.Errorf("entropy is %f bits, expected %f bits", entropy, expected)
+	}
+	config.MinEntropy = 30
+	if err := config.Validate(); err == nil {
+		t.Error("min entropy is satisfied by a single symbol")
+	}
+
+	unlimited := Entropy(&Config{Kinds: []CharacterKind{ALPHABET, SYMBOL}, Length: 16})
+	limited := Entropy(&Config{Kinds: []CharacterKind{ALPHABET, SYMBOL}, Length: 16, MaxDistinctSymbols: 2})
+	if limited > unlimited {
+		t.Errorf("entropy of limited symbols %f exceeds unlimited %f", limited, unlimited)
+	}
+}
+
+func TestPatterns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "patterns.txt")
+	if err := os.WriteFile(path, []byte("[0-9].*[0-9]\r\n\n^[A-Z]\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	patterns, err := LoadPatterns(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patterns) != 2 {
+		t.Fatalf("%d patterns are loaded, want 2", len(patterns))
+	}
+	config := &Config{Kinds: []CharacterKind{ALPHABET, NUMBER}, Length: 12, Patterns: patterns}
+	for i := 0; i < 50; i++ {
+		passwd, err := Generate(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, pattern := range patterns {
+			if !pattern.MatchString(passwd) {
+				t.Fatalf("%q is not matched to %s", passwd, pattern)
+			}
+		}
+	}
+
+	// the failed pattern is reported on exhaustion
+	config.Kinds = []CharacterKind{LOWER}
+	if _, err := Generate(config); err == nil || !strings.Contains(err.Error(), "[0-9].*[0-9]") {
+		t.Errorf("err = %v, want the failed pattern", err)
+	}
+}
+
+func TestLoadPatternsError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "patterns.txt")
+	if err := os.WriteFile(path, []byte("ok\n[unclosed\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPatterns(path); err == nil || !strings.Contains(err.Error(), ":2:") {
+		t.Errorf("err = %v, want the line number", err)
+	}
+}
+
+func TestMinCaseChanges(t *testing.T) {
+	if n := caseChanges([]rune("aB1cD-e")); n != 4 {
+		t.Errorf("caseChanges = %d, want 4 ignoring non letters", n)
+	}
+	config := &Config{Kinds: []CharacterKind{ALPHABET, NUMBER}, Length: 12, MinCaseChanges: 6}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		passwd, err := Generate(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := caseChanges([]rune(passwd)); n < 6 {
+			t.Fatalf("%q has %d case changes", passwd, n)
+		}
+	}
+
+	for _, config := range []*Config{
+		{Kinds: []CharacterKind{LOWER, NUMBER}, Length: 12, MinCaseChanges: 1},
+		{Kinds: []CharacterKind{ALPHABET}, Length: 4, MinCaseChanges: 4},
+	} {
+		if err := config.Validate(); err == nil {
+			t.Errorf("%v cannot be satisfied", config.Kinds)
+		}
+	}
+}
+
+func TestMinShannon(t *testing.T) {
+	if bits := shannon([]rune("aaaaaaaa")); bits != 0 {
+		t.Errorf("shannon of the same characters = %v, want 0", bits)
+	}
+	if bits := shannon([]rune("abcdefgh")); bits != 3 {
+		t.Errorf("shannon of distinct 8 characters = %v, want 3", bits)
+	}
+	config := &Config{Kinds: []CharacterKind{ALPHABET, NUMBER}, Length: 16, MinShannon: 3.5}
+	if reason := violation(config, []rune("aaaaaaaabbbbbbbb")); reason == "" {
+		t.Error("a degenerate password is accepted")
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		passwd, err := Generate(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bits := shannon([]rune(passwd)); bits < 3.5 {
+			t.Fatalf("%q has %v bits per character", passwd, bits)
+		}
+	}
+	// 16 characters have at most 4 bits
+	config.MinShannon = 4.1
+	if err := config.Validate(); err == nil {
+		t.Error("Shannon entropy over log2 of the length is accepted")
+	}
+}
+
+func TestMinTransitions(t *testing.T) {
+	if n := transitions([]rune("aB1c€€+")); n != 5 {
+		t.Errorf("transitions = %d, want 5 with one of characters out of kinds", n)
+	}
+	for _, solver := range []bool{false, true} {
+		config := &Config{Kinds: []CharacterKind{ALPHABET, NUMBER, SYMBOL}, Length: 12, MinTransitions: 8, Solver: solver}
+		if err := config.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 50; i++ {
+			passwd, err := Generate(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := transitions([]rune(passwd)); n < 8 {
+				t.Fatalf("%q has %d transitions", passwd, n)
+			}
+		}
+	}
+
+	for _, config := range []*Config{
+		{Kinds: []CharacterKind{ALPHABET, NUMBER}, Length: 4, MinTransitions: 4},
+		{Kinds: []CharacterKind{NUMBER}, Length: 12, MinTransitions: 1},
+		{Kinds: []CharacterKind{ALPHABET, NUMBER}, Length: 12, MinTransitions: -1},
+	} {
+		if err := config.Validate(); err == nil {
+			t.Errorf("%d transitions of %v in %d characters cannot be satisfied", config.MinTransitions, config.Kinds, config.Length)
+		}
+	}
+}
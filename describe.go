@@ -0,0 +1,103 @@
+package gotpasswd
+
+import (
+	"sort"
+)
+
+// Description describes available kinds and presets, for tools built on top of gotpasswd.
+type Description struct {
+	Kinds        []KindDescription    `json:"kinds"`
+	Profiles     []ProfileDescription `json:"profiles"`
+	Layouts      []CharsetDescription `json:"layouts"`
+	FontFamilies []CharsetDescription `json:"font_families"`
+	// Characters dropped for each context of safe characters
+	SafeContexts []CharsetDescription `json:"safe_contexts"`
+}
+
+type KindDescription struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+type ProfileDescription struct {
+	Name        string         `json:"name"`
+	Kinds       []string       `json:"kinds"`
+	Length      int            `json:"length"`
+	MinKinds    int            `json:"min_kinds"`
+	NoAmbiguous bool           `json:"no_ambiguous"`
+	MaxLength   int            `json:"max_length,omitempty"`
+	MinCounts   map[string]int `json:"min_counts,omitempty"`
+}
+
+type CharsetDescription struct {
+	Name  string `json:"name"`
+	Chars string `json:"chars"`
+}
+
+// Describe builds the description from the live dictionary and presets.
+func Describe() *Description {
+	description := &Description{
+		Kinds:        make([]KindDescription, 0, len(kindNames)),
+		Profiles:     make([]ProfileDescription, 0, len(profiles)),
+		Layouts:      describeCharsets(layouts),
+		FontFamilies: describeCharsets(fontConfusables),
+		SafeContexts: describeCharsets(unsafeChars),
+	}
+
+	kinds := make([]CharacterKind, 0, len(kindNames))
+	for _, kind := range kindNames {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i] < kinds[j] })
+	for _, kind := range kinds {
+		description.Kinds = append(description.Kinds, KindDescription{
+			Name:  kind.String(),
+			Count: len(kindChars(kind)),
+		})
+	}
+
+	for _, name := range sortedKeys(profiles) {
+		profile := profiles[name]
+		kindNames := make([]string, len(profile.Kinds))
+		for i, kind := range profile.Kinds {
+			kindNames[i] = kind.String()
+		}
+		var minCounts map[string]int
+		if len(profile.MinCounts) > 0 {
+			minCounts = make(map[string]int, len(profile.MinCounts))
+			for kind, count := range profile.MinCounts {
+				minCounts[kind.String()] = count
+			}
+		}
+		description.Profiles = append(description.Profiles, ProfileDescription{
+			Name:        name,
+			Kinds:       kindNames,
+			Length:      profile.Length,
+			MinKinds:    profile.MinKinds,
+			NoAmbiguous: profile.NoAmbiguous,
+			MaxLength:   profile.MaxLength,
+			MinCounts:   minCounts,
+		})
+	}
+	return description
+}
+
+func describeCharsets(charsets map[string]string) []CharsetDescription {
+	descriptions := make([]CharsetDescription, 0, len(charsets))
+	for _, name := range sortedKeys(charsets) {
+		descriptions = append(descriptions, CharsetDescription{
+			Name:  name,
+			Chars: charsets[name],
+		})
+	}
+	return descriptions
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
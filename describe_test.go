@@ -0,0 +1,57 @@
+package gotpasswd
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	description := Describe()
+	counts := make(map[string]int)
+	for _, kind := range description.Kinds {
+		counts[kind.Name] = kind.Count
+	}
+	if len(counts) != len(kindNames) {
+		t.Errorf("described %d kinds, want %d", len(counts), len(kindNames))
+	}
+	for name, kind := range kindNames {
+		if counts[name] != len(kindChars(kind)) {
+			t.Errorf("count of %s = %d, want %d", name, counts[name], len(kindChars(kind)))
+		}
+	}
+	for name, want := range map[string]int{"upper": len(dict[UPPER]), "lower": len(dict[LOWER]), "alphabet": 52, "number": 10, "symbol": len(dict[SYMBOL])} {
+		if counts[name] != want {
+			t.Errorf("count of %s = %d, want %d", name, counts[name], want)
+		}
+	}
+
+	names := make([]string, 0)
+	for _, described := range description.Profiles {
+		names = append(names, described.Name)
+		profile := profiles[described.Name]
+		if described.Length != profile.Length || described.MaxLength != profile.MaxLength || len(described.MinCounts) != len(profile.MinCounts) {
+			t.Errorf("profile %+v does not describe %+v", described, profile)
+		}
+	}
+	if !slices.Equal(names, ProfileNames()) {
+		t.Errorf("profiles = %v, want %v", names, ProfileNames())
+	}
+	for _, layout := range description.Layouts {
+		if layouts[layout.Name] != layout.Chars {
+			t.Errorf("layout %s = %q, want %q", layout.Name, layout.Chars, layouts[layout.Name])
+		}
+	}
+
+	b, err := json.Marshal(description)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]json.RawMessage
+	json.Unmarshal(b, &fields)
+	for _, key := range []string{"kinds", "profiles", "layouts", "font_families", "safe_contexts"} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("%s is missing in %s", key, b)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package gotpasswd
+
+import (
+	"io"
+	"strings"
+)
+
+// Names of kinds for the first and last characters besides kind names
+var edgeKindAliases = map[string]string{
+	"letter": "alphabet",
+	"digit":  "number",
+	"alnum":  "alphabet,number",
+}
+
+// ParseEdgeKinds parses kinds which the first or last character must be one of, such as "letter" and "alnum".
+func (self *Config) ParseEdgeKinds(s string) ([]CharacterKind, error) {
+	names := make([]string, 0)
+	for _, name := range strings.Split(s, ",") {
+		if alias, ok := edgeKindAliases[name]; ok {
+			name = alias
+		}
+		names = append(names, name)
+	}
+	return self.ParseKinds(strings.Join(names, ","))
+}
+
+func containsAnyKind(kinds []CharacterKind, r rune) bool {
+	for _, kind := range kinds {
+		if kindContains(kind, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// edgeAlphabets returns candidates allowed for the first and last positions among length characters,
+// alphabets of Positions are narrowed instead of candidates at their positions.
+func (self *Config) edgeAlphabets(charCandidates []rune, length int) map[int][]rune {
+	alphabets := make(map[int][]rune)
+	for _, edge := range []struct {
+		index int
+		kinds []CharacterKind
+	}{{0, self.StartWith}, {length - 1, self.EndWith}} {
+		if len(edge.kinds) == 0 || edge.index < 0 {
+			continue
+		}
+		allowed := charCandidates
+		if alphabet, ok := self.Positions[edge.index]; ok {
+			allowed = alphabet
+		}
+		if prev, ok := alphabets[edge.index]; ok {
+			allowed = prev
+		}
+		alphabets[edge.index] = filterRunes(allowed, func(r rune) bool {
+			return containsAnyKind(edge.kinds, r)
+		})
+	}
+	return alphabets
+}
+
+// fillEdges replaces the first and last characters by ones of StartWith and EndWith.
+func fillEdges(r io.Reader, config *Config, charCandidates []rune, chars []rune) error {
+	return fillPositions(r, config.edgeAlphabets(charCandidates, len(chars)), chars)
+}
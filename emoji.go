@@ -0,0 +1,10 @@
+package gotpasswd
+
+// Emoji for EMOJI, single codepoints presented as emoji by default, so that no variation selectors
+// nor joiners are needed and each of them counts as one character of the length.
+var emojiRanges = [][2]rune{
+	// Emoticons
+	{0x1f600, 0x1f64f},
+	// Transport and Map Symbols
+	{0x1f680, 0x1f6c5},
+}
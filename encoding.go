@@ -0,0 +1,33 @@
+package gotpasswd
+
+import (
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// Target encodings, characters are encodable when they round-trip through them.
+var targetEncodings = map[string]encoding.Encoding{
+	"latin1":   charmap.ISO8859_1,
+	"shiftjis": japanese.ShiftJIS,
+}
+
+func encodable(chars []rune, name string) bool {
+	return roundTrips(name)(string(chars))
+}
+
+// roundTrips returns a function reporting whether s round-trips through the encoding,
+// which reuses its encoder and decoder for filtering many characters.
+func roundTrips(name string) func(s string) bool {
+	target := targetEncodings[name]
+	encoder := target.NewEncoder()
+	decoder := target.NewDecoder()
+	return func(s string) bool {
+		encoded, err := encoder.String(s)
+		if err != nil {
+			return false
+		}
+		decoded, err := decoder.String(encoded)
+		return err == nil && decoded == s
+	}
+}
@@ -0,0 +1,94 @@
+package gotpasswd
+
+import (
+	"slices"
+	"testing"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestTargetEncodingLatin1(t *testing.T) {
+	config := &Config{Length: 32}
+	kinds, err := config.ParseKinds("latin,number")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Kinds = kinds
+	if !slices.ContainsFunc(Candidates(config), func(r rune) bool { return r > 0xff }) {
+		t.Fatal("latin letters must be out of latin1 without the target encoding")
+	}
+
+	config.TargetEncoding = "latin1"
+	candidates := Candidates(config)
+	if !slices.Contains(candidates, 'é') {
+		t.Errorf("%q must be left in the candidates", 'é')
+	}
+	for i := 0; i < 50; i++ {
+		passwd, err := Generate(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// latin1 is the first 256 code points
+		encoded := make([]byte, 0)
+		for _, r := range passwd {
+			if r > 0xff {
+				t.Fatalf("%q is not encodable in latin1", r)
+			}
+			encoded = append(encoded, byte(r))
+		}
+		decoded := make([]rune, len(encoded))
+		for i, b := range encoded {
+			decoded[i] = rune(b)
+		}
+		if string(decoded) != passwd {
+			t.Fatalf("%q does not round-trip", passwd)
+		}
+	}
+}
+
+func TestTargetEncodingShiftJIS(t *testing.T) {
+	config := &Config{Length: 8, TargetEncoding: "shiftjis"}
+	kinds, err := config.ParseKinds("han,kana")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Kinds = kinds
+	candidates := Candidates(config)
+	// kanji of JIS X 0208 are left, ones out of it are not
+	for _, r := range "亜漢あア" {
+		if !slices.Contains(candidates, r) {
+			t.Errorf("%q must be left in the candidates", r)
+		}
+	}
+	for _, r := range "丂𠀋" {
+		if slices.Contains(candidates, r) {
+			t.Errorf("%q is not encodable in shiftjis", r)
+		}
+	}
+	for i := 0; i < 50; i++ {
+		passwd, err := Generate(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded, err := japanese.ShiftJIS.NewEncoder().String(passwd)
+		if err != nil {
+			t.Fatalf("%q is not encodable in shiftjis: %v", passwd, err)
+		}
+		if decoded, err := japanese.ShiftJIS.NewDecoder().String(encoded); err != nil || decoded != passwd {
+			t.Fatalf("%q does not round-trip, got %q", passwd, decoded)
+		}
+	}
+}
+
+func TestTargetEncodingEmpty(t *testing.T) {
+	// hangul are out of JIS X 0208
+	config := &Config{Length: 8, TargetEncoding: "shiftjis"}
+	kinds, err := config.ParseKinds("hangul")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Kinds = kinds
+	if err := config.Validate(); err == nil {
+		t.Error("no characters are left, it must fail")
+	}
+}
@@ -0,0 +1,12 @@
+package gotpasswd
+
+// Characters confused with each other when rendered in a font family.
+var fontConfusables = map[string]string{
+	// 0/O, 1/l/I/| and '/` still look alike in many monospace fonts
+	"monospace": "0O1lI|'`",
+	// In addition to the monospace set, serif fonts blur rn/m, vv/w and cl/d
+	"serif": "0O1lI|'`" + "rnmvwcd",
+}
+
+// Characters confused with each other in print or over the phone regardless of the font, same as pwgen -B with '|'
+const ambiguousChars = "B8G6I1l0OQDS5Z2|"
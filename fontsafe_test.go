@@ -0,0 +1,41 @@
+package gotpasswd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFontSafe(t *testing.T) {
+	for font, excluded := range fontConfusables {
+		// extra characters are excluded too
+		config := &Config{Kinds: []CharacterKind{ALPHABET, NUMBER, SYMBOL}, ExtraChars: []rune("0Ormn"), Length: 64, FontSafe: font}
+		if err := config.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		if candidates := string(Candidates(config)); strings.ContainsAny(candidates, excluded) {
+			t.Fatalf("candidates %q of %s contain excluded glyphs", candidates, font)
+		}
+		for i := 0; i < 50; i++ {
+			passwd, err := Generate(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if i := strings.IndexAny(passwd, excluded); i >= 0 {
+				t.Fatalf("%q contains %q confused in %s", passwd, passwd[i], font)
+			}
+		}
+	}
+}
+
+func TestNoAmbiguous(t *testing.T) {
+	config := &Config{Kinds: []CharacterKind{ALPHABET, NUMBER, SYMBOL}, Length: 64, NoAmbiguous: true}
+	for i := 0; i < 50; i++ {
+		passwd, err := Generate(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i := strings.IndexAny(passwd, ambiguousChars); i >= 0 {
+			t.Fatalf("%q contains the ambiguous %q", passwd, passwd[i])
+		}
+	}
+}
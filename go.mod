@@ -0,0 +1,5 @@
+module github.com/kamichidu/go-gotpasswd
+
+go 1.24.0
+
+require golang.org/x/text v0.31.0
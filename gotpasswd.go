@@ -0,0 +1,431 @@
+// Package gotpasswd generates random passwords, based on crypto/rand package.
+package gotpasswd
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+type CharacterKind int
+
+const (
+	// ALPHABET is an alias of UPPER and LOWER, KindOf never returns it
+	ALPHABET CharacterKind = iota
+	NUMBER
+	SYMBOL
+	UNDERSCORE
+	SPACE
+	UPPER
+	LOWER
+	EMOJI
+
+	firstUnicodeKind CharacterKind = 100
+)
+
+// Names of character kinds for ParseKinds
+var kindNames = map[string]CharacterKind{
+	"alphabet":   ALPHABET,
+	"number":     NUMBER,
+	"symbol":     SYMBOL,
+	"underscore": UNDERSCORE,
+	"space":      SPACE,
+	"upper":      UPPER,
+	"lower":      LOWER,
+	"emoji":      EMOJI,
+}
+
+func (self CharacterKind) String() string {
+	for name, kind := range kindNames {
+		if kind == self {
+			return name
+		}
+	}
+	return fmt.Sprintf("CharacterKind(%d)", int(self))
+}
+
+var (
+	dict map[CharacterKind]([]rune)
+)
+
+func init() {
+	dict = make(map[CharacterKind]([]rune))
+	// Auto generate dictionary using ascii printable characters
+	for code := 0x20; code <= 0x7e; code++ {
+		r := rune(code)
+		if !unicode.IsPrint(r) {
+			panic("Internal error, cannot construct character dictionary")
+		}
+
+		// if r == '_' {
+		// 	fmt.Printf("unicode.IsControl('%c') = %v\n", r, unicode.IsControl(r))
+		// 	fmt.Printf("unicode.IsDigit('%c') = %v\n", r, unicode.IsDigit(r))
+		// 	fmt.Printf("unicode.IsGraphic('%c') = %v\n", r, unicode.IsGraphic(r))
+		// 	fmt.Printf("unicode.IsLetter('%c') = %v\n", r, unicode.IsLetter(r))
+		// 	fmt.Printf("unicode.IsLower('%c') = %v\n", r, unicode.IsLower(r))
+		// 	fmt.Printf("unicode.IsMark('%c') = %v\n", r, unicode.IsMark(r))
+		// 	fmt.Printf("unicode.IsNumber('%c') = %v\n", r, unicode.IsNumber(r))
+		// 	fmt.Printf("unicode.IsPrint('%c') = %v\n", r, unicode.IsPrint(r))
+		// 	fmt.Printf("unicode.IsPunct('%c') = %v\n", r, unicode.IsPunct(r))
+		// 	fmt.Printf("unicode.IsSpace('%c') = %v\n", r, unicode.IsSpace(r))
+		// 	fmt.Printf("unicode.IsSymbol('%c') = %v\n", r, unicode.IsSymbol(r))
+		// 	fmt.Printf("unicode.IsTitle('%c') = %v\n", r, unicode.IsTitle(r))
+		// 	fmt.Printf("unicode.IsUpper('%c') = %v\n", r, unicode.IsUpper(r))
+		// }
+
+		switch {
+		case unicode.IsLetter(r):
+			if unicode.IsUpper(r) {
+				dict[UPPER] = append(dict[UPPER], r)
+			} else {
+				dict[LOWER] = append(dict[LOWER], r)
+			}
+		case unicode.IsNumber(r):
+			dict[NUMBER] = append(dict[NUMBER], r)
+		case unicode.IsSymbol(r):
+			dict[SYMBOL] = append(dict[SYMBOL], r)
+		case unicode.IsSpace(r):
+			dict[SPACE] = append(dict[SPACE], r)
+		case r == '_':
+			dict[UNDERSCORE] = append(dict[UNDERSCORE], r)
+		}
+	}
+	for _, bounds := range emojiRanges {
+		for r := bounds[0]; r <= bounds[1]; r++ {
+			dict[EMOJI] = append(dict[EMOJI], r)
+		}
+	}
+}
+
+type Config struct {
+	Kinds    []CharacterKind
+	Length   int
+	Num      int
+	Layout   string
+	NoShift  bool
+	FontSafe string
+	Grammar  Grammar
+	// Characters used instead of Kinds, and added to them
+	Chars      []rune
+	ExtraChars []rune
+	// Exclude characters confusable regardless of the font, such as 0/O and 1/l/I
+	NoAmbiguous bool
+	// Characters removed from candidates
+	Exclude []rune
+	// Name of the encoding which passwords must be encodable in
+	TargetEncoding string
+	// SQL dialect whose string literals passwords must not need escapes in
+	SQLSafe string
+	// Contexts such as shell and url which passwords must be usable in without quotes nor escapes
+	SafeFor []string
+	// Lengths chosen randomly for each password, Length is the minimum of them
+	AllowedLengths []int
+	// Number of words of passphrases, and digits appended to them
+	Words     int
+	PinSuffix int
+	// Name of the wordlist of passphrases, english if empty
+	Wordlist string
+	// Words of passphrases such as loaded by LoadWordlist, overrides Wordlist
+	CustomWordlist []string
+	// Separator of words of passphrases, "-" if nil
+	Separator *string
+	// Capitalization of words of passphrases (none, first, random, all)
+	Capitalize string
+	// Number of digits and symbols inserted at random positions of passphrases
+	InjectDigits  int
+	InjectSymbols int
+	// Number of words of BIP39 mnemonics encoded by MnemonicWordlist, one of 12, 15, 18, 21 and 24
+	Mnemonic int
+	// The official English wordlist if nil
+	MnemonicWordlist []string
+	// Generate API tokens of base62 bodies of Length in the format
+	Token *TokenFormat
+	// Encode Length random bytes instead of drawing characters (hex, base64, base64url, base58)
+	RawEncoding string
+	// Generate identifiers instead of passwords (uuid4, uuid7, ulid)
+	ID string
+	// Charsets drawn by their weights instead of Candidates
+	WeightedCharsets []WeightedCharset
+	// Alternate consonant and vowel phonemes like pwgen, mixing upper cases, digits and symbols in by Kinds
+	Pronounceable bool
+	// Sample characters from the n-gram model instead of Candidates
+	Markov *MarkovModel
+	// Alphabets for each position, others are drawn from Candidates
+	Positions map[int][]rune
+
+	// Construct passwords satisfying constraints as possible, instead of regenerating
+	Solver bool
+
+	// Constraints which generated passwords must satisfy
+	// Kinds which the first and last characters must be one of
+	StartWith         []CharacterKind
+	EndWith           []CharacterKind
+	NoRepeatedBigrams bool
+	// Use each character at most once, or never twice in a row
+	NoRepeat         bool
+	NoAdjacentRepeat bool
+	MinKinds         int
+	// Every kind of Kinds must appear, placed without bias by the solver
+	RequireAllKinds bool
+	MinTransitions  int
+	MinCaseChanges  int
+	// Min and max numbers of characters of each kind, ALPHABET counts both of UPPER and LOWER
+	MinCounts map[CharacterKind]int
+	MaxCounts map[CharacterKind]int
+	// Min empirical Shannon entropy in bits per character
+	MinShannon float64
+	// Reject passwords which are dictionary words disguised by l33t substitutions
+	NoLeetWords bool
+	// Reject passwords embedding words of DictWords, or weak words and passphrase words if nil
+	NoDictWords bool
+	DictWords   []string
+	// Max length of runs of adjacent keys on keyboards, such as qwerty and asdf, and of sequential letters and digits
+	MaxWalk int
+	// Reject PINs of repeated patterns, ascending or descending runs and common years
+	NoTrivialPins bool
+	// Max number of each kind in any window of consecutive characters
+	KindWindow *KindWindow
+	// Max number of distinct symbol characters in a password
+	MaxDistinctSymbols int
+	// Min theoretical entropy in bits, Validate fails below it
+	MinEntropy float64
+	// Flagged words which must not appear, also excluded from passphrase words
+	Blocklist []string
+	// Groups of characters which passwords must contain one of each
+	RequireOneOf [][]rune
+	// Regular expressions which passwords must match all of
+	Patterns []*regexp.Regexp
+	// External validator which passwords must be accepted by
+	Validator Validator
+}
+
+// usesCandidates reports whether passwords are drawn from Candidates.
+func (self *Config) usesCandidates() bool {
+	return self.Grammar == nil && self.Words == 0 && self.WeightedCharsets == nil && !self.Pronounceable && self.Markov == nil && self.Mnemonic == 0 && self.Token == nil && self.RawEncoding == "" && self.ID == ""
+}
+
+func (self *Config) ParseKinds(s string) ([]CharacterKind, error) {
+	kinds := make([]CharacterKind, 0)
+	for _, candidate := range strings.Split(s, ",") {
+		kind, ok := kindNames[candidate]
+		if !ok {
+			return kinds, errors.New(fmt.Sprintf("Unknown character kind: %s", candidate))
+		}
+		kinds = append(kinds, kind)
+	}
+	return kinds, nil
+}
+
+// kindChars returns characters of the kind, expanding ALPHABET and unicode kinds.
+func kindChars(kind CharacterKind) []rune {
+	if kind == ALPHABET {
+		return append(append([]rune(nil), dict[UPPER]...), dict[LOWER]...)
+	}
+	if _, ok := unicodeKinds[kind]; ok {
+		return unicodeKindChars(kind)
+	}
+	return dict[kind]
+}
+
+func Candidates(config *Config) []rune {
+	charCandidates := make([]rune, 0)
+	if config.Chars != nil {
+		charCandidates = append(charCandidates, config.Chars...)
+	} else {
+		for _, kindIndex := range config.Kinds {
+			charCandidates = append(charCandidates, kindChars(kindIndex)...)
+		}
+	}
+	// kinds may overlap such as alphabet and upper, and so may extra characters
+	charCandidates = uniqueRunes(append(charCandidates, config.ExtraChars...))
+	if config.Layout != "" {
+		charCandidates = filterRunes(charCandidates, func(r rune) bool {
+			return strings.ContainsRune(layouts[config.Layout], r)
+		})
+	}
+	if config.NoShift {
+		charCandidates = filterRunes(charCandidates, func(r rune) bool {
+			return strings.ContainsRune(unshiftedKeys, r)
+		})
+	}
+	if config.NoAmbiguous {
+		charCandidates = filterRunes(charCandidates, func(r rune) bool {
+			return !strings.ContainsRune(ambiguousChars, r)
+		})
+	}
+	if config.FontSafe != "" {
+		charCandidates = filterRunes(charCandidates, func(r rune) bool {
+			return !strings.ContainsRune(fontConfusables[config.FontSafe], r)
+		})
+	}
+	if config.SQLSafe != "" {
+		charCandidates = filterRunes(charCandidates, func(r rune) bool {
+			return !strings.ContainsRune(sqlDialects[config.SQLSafe], r)
+		})
+	}
+	for _, context := range config.SafeFor {
+		charCandidates = filterRunes(charCandidates, func(r rune) bool {
+			return safeFor(context, r)
+		})
+	}
+	if config.TargetEncoding != "" {
+		encodable := roundTrips(config.TargetEncoding)
+		charCandidates = filterRunes(charCandidates, func(r rune) bool {
+			return encodable(string(r))
+		})
+	}
+	if len(config.Exclude) > 0 {
+		charCandidates = filterRunes(charCandidates, func(r rune) bool {
+			return !strings.ContainsRune(string(config.Exclude), r)
+		})
+	}
+	return charCandidates
+}
+
+func filterRunes(runes []rune, keep func(rune) bool) []rune {
+	filtered := make([]rune, 0, len(runes))
+	for _, r := range runes {
+		if keep(r) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// Max number of regenerations until a password satisfies all constraints
+const maxRetries = 1000
+
+func Generate(config *Config) (string, error) {
+	return GenerateFrom(rand.Reader, config)
+}
+
+// GenerateContext is same as Generate, but gives up regenerations when ctx is done.
+func GenerateContext(ctx context.Context, config *Config) (string, error) {
+	passwd, _, err := generate(ctx, config, rand.Reader)
+	return passwd, err
+}
+
+// GenerateFrom is same as Generate, but draws randomness from r, such as a hardware RNG device.
+// Passwords are no more secret than r, it must return uniformly random bytes.
+func GenerateFrom(r io.Reader, config *Config) (string, error) {
+	passwd, _, err := generate(context.Background(), config, r)
+	return passwd, err
+}
+
+// generate returns a password and its length, chosen from AllowedLengths if any.
+func generate(ctx context.Context, config *Config, r io.Reader) (string, int, error) {
+	charCandidates := Candidates(config)
+
+	if config.usesCandidates() && len(charCandidates) == 0 {
+		return "", 0, errors.New("Internal error, cannot work with empty candidates")
+	}
+
+	length := config.Length
+	if len(config.AllowedLengths) > 0 {
+		index, err := randomInt(r, len(config.AllowedLengths))
+		if err != nil {
+			return "", 0, err
+		}
+		length = config.AllowedLengths[index]
+	}
+	if config.MaxDistinctSymbols > 0 {
+		limited, err := limitSymbols(r, charCandidates, config.MaxDistinctSymbols)
+		if err != nil {
+			return "", 0, err
+		}
+		charCandidates = limited
+	}
+
+	var reason string
+	for retry := 0; retry < maxRetries; retry++ {
+		if err := ctx.Err(); err != nil {
+			return "", 0, err
+		}
+		var (
+			chars []rune
+			err   error
+		)
+		if config.Grammar != nil {
+			chars, err = config.Grammar.generate(r)
+		} else if config.ID != "" {
+			chars, err = generateID(r, config.ID)
+		} else if config.RawEncoding != "" {
+			chars, err = generateRawEncoded(r, config.RawEncoding, length)
+		} else if config.Token != nil {
+			chars, err = generateToken(r, config.Token, length)
+		} else if config.Mnemonic > 0 {
+			chars, err = generateMnemonic(r, config)
+		} else if config.Words > 0 {
+			chars, err = generatePassphrase(r, config)
+		} else if config.Pronounceable {
+			chars, err = generatePronounceable(r, config, length)
+		} else if config.Markov != nil {
+			chars, err = config.Markov.generate(r, length)
+		} else if config.WeightedCharsets != nil {
+			chars, err = generateWeighted(r, config.WeightedCharsets, length)
+		} else if config.Solver || config.RequireAllKinds {
+			chars, err = solve(r, config, charCandidates, length)
+		} else {
+			if config.KindWindow != nil {
+				chars, err = randomRunesInWindow(r, charCandidates, length, config.KindWindow)
+			} else if config.NoRepeat || config.NoAdjacentRepeat {
+				chars, err = randomRunesWithoutRepeat(r, charCandidates, length, !config.NoRepeat)
+			} else if config.NoRepeatedBigrams {
+				chars, err = randomRunesWithoutRepeatedBigrams(r, charCandidates, length)
+			} else {
+				chars, err = randomRunes(r, charCandidates, length)
+			}
+			if err == nil {
+				err = fillPositions(r, config.Positions, chars)
+			}
+			if err == nil {
+				err = fillEdges(r, config, charCandidates, chars)
+			}
+		}
+		if err != nil {
+			return "", 0, err
+		}
+		reason = violation(config, chars)
+		if reason == "" && config.Validator != nil {
+			ok, err := config.Validator.Validate(ctx, string(chars))
+			if err != nil {
+				return "", 0, err
+			} else if !ok {
+				reason = "the validator"
+			}
+		}
+		if reason == "" {
+			return string(chars), length, nil
+		}
+	}
+	return "", 0, errors.New(fmt.Sprintf("Cannot generate a password satisfying constraints in %d retries, last one was rejected for %s", maxRetries, reason))
+}
+
+func randomRunes(r io.Reader, charCandidates []rune, length int) ([]rune, error) {
+	chars := make([]rune, length)
+	i := 0
+	for i < length {
+		charIndex, err := randomInt(r, len(charCandidates))
+		if err != nil {
+			return nil, err
+		}
+		chars[i] = charCandidates[charIndex]
+		i++
+	}
+	return chars, nil
+}
+
+// randomInt returns a uniform random number in [0, n).
+func randomInt(r io.Reader, n int) (int, error) {
+	v, err := rand.Int(r, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, errors.New(fmt.Sprintf("Cannot read randomness: %s", err))
+	}
+	return int(v.Int64()), nil
+}
@@ -0,0 +1,62 @@
+package gotpasswd
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// slowRejector rejects every password after a while, a constraint which cannot be satisfied
+type slowRejector struct{}
+
+func (slowRejector) Validate(ctx context.Context, passwd string) (bool, error) {
+	time.Sleep(time.Millisecond)
+	return false, nil
+}
+
+func TestGenerateTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := GenerateContext(ctx, &Config{
+		Kinds:     []CharacterKind{ALPHABET},
+		Length:    12,
+		Validator: slowRejector{},
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want the deadline", err)
+	}
+	// 1000 retries take a second
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("generation took %s after the deadline", elapsed)
+	}
+}
+
+func TestAllowedLengths(t *testing.T) {
+	config := &Config{Kinds: []CharacterKind{ALPHABET}, Length: 6, AllowedLengths: []int{6, 9, 12}}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	generator := NewGenerator(nil)
+	drawn := make(map[int]float64)
+	for i := 0; i < 200; i++ {
+		passwd, err := generator.GeneratePassword(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		length := len(passwd.Value)
+		if !slices.Contains(config.AllowedLengths, length) {
+			t.Fatalf("length of %q is not allowed", passwd.Value)
+		}
+		drawn[length] = passwd.Entropy
+	}
+	// each of them is drawn once in 3, missing one in 200 draws is of (2/3)^200
+	if len(drawn) != 3 {
+		t.Errorf("drawn lengths = %v, want all of the allowed ones", drawn)
+	}
+	if !(drawn[6] < drawn[9] && drawn[9] < drawn[12]) {
+		t.Errorf("entropies of lengths = %v, want increasing by lengths", drawn)
+	}
+}
@@ -0,0 +1,189 @@
+package gotpasswd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Grammar describes a structure of passwords, such as "[A-Z]{2}[0-9]{4}[!@#]".
+// Each term is a bracket class (with ranges like "a-z") or a literal character,
+// optionally followed by a quantifier "{n}" or "{min,max}".
+// A backslash escapes the following character.
+type Grammar []GrammarTerm
+
+// Maximum length of passwords generated by a grammar, and of each quantifier
+const maxGrammarLength = 1024
+
+type GrammarTerm struct {
+	Chars []rune
+	Min   int
+	Max   int
+}
+
+func ParseGrammar(s string) (Grammar, error) {
+	src := []rune(s)
+	grammar := make(Grammar, 0)
+	for i := 0; i < len(src); {
+		var term GrammarTerm
+		switch src[i] {
+		case '[':
+			end := i + 1
+			for end < len(src) && src[end] != ']' {
+				if src[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(src) {
+				return nil, errors.New(fmt.Sprintf("Unterminated character class at %d in grammar", i))
+			}
+			chars, err := parseGrammarClass(src[i+1 : end])
+			if err != nil {
+				return nil, err
+			}
+			term.Chars = chars
+			i = end + 1
+		case ']', '{', '}':
+			return nil, errors.New(fmt.Sprintf("Unexpected '%c' at %d in grammar", src[i], i))
+		case '\\':
+			if i+1 >= len(src) {
+				return nil, errors.New("Trailing backslash in grammar")
+			}
+			term.Chars = []rune{src[i+1]}
+			i += 2
+		default:
+			term.Chars = []rune{src[i]}
+			i++
+		}
+
+		term.Min, term.Max = 1, 1
+		if i < len(src) && src[i] == '{' {
+			end := i + 1
+			for end < len(src) && src[end] != '}' {
+				end++
+			}
+			if end >= len(src) {
+				return nil, errors.New(fmt.Sprintf("Unterminated quantifier at %d in grammar", i))
+			}
+			min, max, err := parseGrammarQuantifier(string(src[i+1 : end]))
+			if err != nil {
+				return nil, err
+			}
+			term.Min, term.Max = min, max
+			i = end + 1
+		}
+		grammar = append(grammar, term)
+		if grammar.maxLength() > maxGrammarLength {
+			return nil, errors.New(fmt.Sprintf("Grammar generates passwords longer than %d characters", maxGrammarLength))
+		}
+	}
+
+	if grammar.maxLength() == 0 {
+		return nil, errors.New("Grammar generates empty passwords")
+	}
+	return grammar, nil
+}
+
+func parseGrammarClass(src []rune) ([]rune, error) {
+	seen := make(map[rune]bool)
+	chars := make([]rune, 0)
+	add := func(r rune) {
+		if !seen[r] {
+			seen[r] = true
+			chars = append(chars, r)
+		}
+	}
+	for i := 0; i < len(src); i++ {
+		lo := src[i]
+		if lo == '\\' {
+			i++
+			lo = src[i]
+		}
+		if i+2 < len(src) && src[i+1] == '-' {
+			hi := src[i+2]
+			i += 2
+			if hi == '\\' {
+				if i+1 >= len(src) {
+					return nil, errors.New("Trailing backslash in grammar character class")
+				}
+				i++
+				hi = src[i]
+			}
+			if lo > hi {
+				return nil, errors.New(fmt.Sprintf("Invalid range %c-%c in grammar", lo, hi))
+			}
+			for r := lo; r <= hi; r++ {
+				add(r)
+			}
+		} else {
+			add(lo)
+		}
+	}
+	if len(chars) == 0 {
+		return nil, errors.New("Empty character class in grammar")
+	}
+	return chars, nil
+}
+
+func parseGrammarQuantifier(s string) (int, int, error) {
+	bounds := strings.SplitN(s, ",", 2)
+	min, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+	if err != nil {
+		return 0, 0, errors.New(fmt.Sprintf("Invalid quantifier {%s} in grammar", s))
+	}
+	max := min
+	if len(bounds) == 2 {
+		max, err = strconv.Atoi(strings.TrimSpace(bounds[1]))
+		if err != nil {
+			return 0, 0, errors.New(fmt.Sprintf("Invalid quantifier {%s} in grammar", s))
+		}
+	}
+	if min < 0 || min > max {
+		return 0, 0, errors.New(fmt.Sprintf("Invalid quantifier {%s} in grammar", s))
+	} else if max > maxGrammarLength {
+		return 0, 0, errors.New(fmt.Sprintf("Quantifier {%s} in grammar exceeds %d", s, maxGrammarLength))
+	}
+	return min, max, nil
+}
+
+func (self Grammar) maxLength() int {
+	length := 0
+	for _, term := range self {
+		length += term.Max
+	}
+	return length
+}
+
+// Entropy returns the entropy in bits of passwords generated by the grammar.
+// Optional repetitions are not counted, so it's a lower bound.
+func (self Grammar) Entropy() float64 {
+	entropy := 0.0
+	for _, term := range self {
+		entropy += float64(term.Min) * math.Log2(float64(len(term.Chars)))
+	}
+	return entropy
+}
+
+func (self Grammar) generate(r io.Reader) ([]rune, error) {
+	chars := make([]rune, 0, self.maxLength())
+	for _, term := range self {
+		count := term.Min
+		if term.Max > term.Min {
+			extra, err := randomInt(r, term.Max-term.Min+1)
+			if err != nil {
+				return nil, err
+			}
+			count += extra
+		}
+		termChars, err := randomRunes(r, term.Chars, count)
+		if err != nil {
+			return nil, err
+		}
+		chars = append(chars, termChars...)
+	}
+	return chars, nil
+}
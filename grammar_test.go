@@ -0,0 +1,45 @@
+package gotpasswd
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestGrammar(t *testing.T) {
+	grammar, err := ParseGrammar(`[A-Z]{2}[0-9]{4}[!@#]-[a-f\-]{1,3}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pattern := regexp.MustCompile(`^[A-Z]{2}[0-9]{4}[!@#]-[a-f\-]{1,3}$`)
+	config := &Config{Grammar: grammar, Length: 1}
+	for i := 0; i < 100; i++ {
+		passwd, err := Generate(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !pattern.MatchString(passwd) {
+			t.Fatalf("%q is not of the grammar", passwd)
+		}
+	}
+}
+
+func TestParseGrammarErrors(t *testing.T) {
+	for _, s := range []string{
+		"[A-Z",
+		"[]",
+		"[z-a]",
+		"a{2",
+		"a{3,1}",
+		"a{x}",
+		"a}",
+		`a\`,
+		"a{0}",
+		"a{99999999999}",
+		"a{1,1025}",
+		"[a-z]{1000}[0-9]{25}",
+	} {
+		if _, err := ParseGrammar(s); err == nil {
+			t.Errorf("ParseGrammar(%q) must fail", s)
+		}
+	}
+}
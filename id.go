@@ -0,0 +1,62 @@
+package gotpasswd
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Crockford's base32 of ULID
+const crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// Identifiers of Config.ID, with bits of randomness of them
+var identifiers = map[string]float64{
+	"uuid4": 122,
+	"uuid7": 74,
+	"ulid":  80,
+}
+
+func generateID(r io.Reader, kind string) ([]rune, error) {
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, errors.New(fmt.Sprintf("Cannot read randomness: %s", err))
+	}
+	millis := uint64(time.Now().UnixMilli())
+	switch kind {
+	case "uuid4", "uuid7":
+		version := byte(4)
+		if kind == "uuid7" {
+			version = 7
+			// 48 bits of milliseconds in big endian
+			var ts [8]byte
+			binary.BigEndian.PutUint64(ts[:], millis)
+			copy(b[:6], ts[2:])
+		}
+		b[6] = b[6]&0x0f | version<<4
+		// variant of RFC 9562
+		b[8] = b[8]&0x3f | 0x80
+		s := hex.EncodeToString(b)
+		return []rune(s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]), nil
+	case "ulid":
+		var ts [8]byte
+		binary.BigEndian.PutUint64(ts[:], millis)
+		copy(b[:6], ts[2:])
+		// 128 bits in 26 characters of 5 bits, the first one has only 3 bits
+		chars := make([]rune, 26)
+		for i := range chars {
+			index := 0
+			for bit := i*5 - 2; bit < i*5+3; bit++ {
+				index <<= 1
+				if bit >= 0 {
+					index |= int(b[bit/8]>>(7-bit%8)) & 1
+				}
+			}
+			chars[i] = rune(crockfordBase32[index])
+		}
+		return chars, nil
+	}
+	return nil, errors.New(fmt.Sprintf("Unknown identifier: %s", kind))
+}
@@ -0,0 +1,121 @@
+package gotpasswd
+
+import "unicode"
+
+// Rows of keyboards, keys typed without and with shift. Keys left of the bottom row of ISO keyboards are omitted.
+var keyboards = map[string][][2]string{
+	"qwerty": {
+		{"`1234567890-=", "~!@#$%^&*()_+"},
+		{"qwertyuiop[]\\", "QWERTYUIOP{}|"},
+		{"asdfghjkl;'", "ASDFGHJKL:\""},
+		{"zxcvbnm,./", "ZXCVBNM<>?"},
+	},
+	"qwertz": {
+		{"^1234567890ß´", "°!\"§$%&/()=?`"},
+		{"qwertzuiopü+", "QWERTZUIOPÜ*"},
+		{"asdfghjklöä#", "ASDFGHJKLÖÄ'"},
+		{"yxcvbnm,.-", "YXCVBNM;:_"},
+	},
+	"azerty": {
+		{"²&é\"'(-è_çà)=", "³1234567890°+"},
+		{"azertyuiop^$", "AZERTYUIOP¨£"},
+		{"qsdfghjklmù*", "QSDFGHJKLM%µ"},
+		{"wxcvbn,;:!", "WXCVBN?./§"},
+	},
+	"dvorak": {
+		{"`1234567890[]", "~!@#$%^&*(){}"},
+		{"',.pyfgcrl/=\\", "\"<>PYFGCRL?+|"},
+		{"aoeuidhtns-", "AOEUIDHTNS_"},
+		{";qjkxbmwvz", ":QJKXBMWVZ"},
+	},
+}
+
+type keyPosition struct {
+	row, col int
+	shifted  bool
+}
+
+// Offsets of adjacent keys of staggered rows, the row below is shifted right by a half key
+var keyOffsets = [][2]int{{0, -1}, {0, 1}, {-1, 0}, {-1, 1}, {1, -1}, {1, 0}}
+
+// KeyDirections is the number of directions to adjacent keys.
+const KeyDirections = 6
+
+var keyPositions = func() map[string]map[rune]keyPosition {
+	positions := make(map[string]map[rune]keyPosition)
+	for name, rows := range keyboards {
+		keys := make(map[rune]keyPosition)
+		for row, keysOfRow := range rows {
+			for col, r := range []rune(keysOfRow[0]) {
+				keys[r] = keyPosition{row, col, false}
+			}
+			for col, r := range []rune(keysOfRow[1]) {
+				keys[r] = keyPosition{row, col, true}
+			}
+		}
+		positions[name] = keys
+	}
+	return positions
+}()
+
+// KeyDirection returns the direction in [0, KeyDirections) from a to b adjacent on the keyboard, or -1.
+func KeyDirection(keyboard string, a, b rune) int {
+	from, ok1 := keyPositions[keyboard][a]
+	to, ok2 := keyPositions[keyboard][b]
+	if !ok1 || !ok2 {
+		return -1
+	}
+	for direction, offset := range keyOffsets {
+		if to.row-from.row == offset[0] && to.col-from.col == offset[1] {
+			return direction
+		}
+	}
+	return -1
+}
+
+// IsShiftedKey reports whether r is typed with shift on the keyboard.
+func IsShiftedKey(keyboard string, r rune) bool {
+	return keyPositions[keyboard][r].shifted
+}
+
+// KeyCount returns the number of keys of the keyboard, not counting shift.
+func KeyCount(keyboard string) int {
+	count := 0
+	for _, row := range keyboards[keyboard] {
+		count += len([]rune(row[0]))
+	}
+	return count
+}
+
+// longestWalk returns the length of the longest run of adjacent keys on any keyboard,
+// or of sequential letters and digits such as "abcd" and "4321".
+func longestWalk(chars []rune) int {
+	longest := min(len(chars), 1)
+	for keyboard := range keyboards {
+		run := 1
+		for i := 1; i < len(chars); i++ {
+			if KeyDirection(keyboard, chars[i-1], chars[i]) >= 0 {
+				run++
+			} else {
+				run = 1
+			}
+			longest = max(longest, run)
+		}
+	}
+	sequential := func(a, b rune, delta rune) bool {
+		a, b = unicode.ToLower(a), unicode.ToLower(b)
+		return b-a == delta && ((a >= 'a' && a <= 'z' && b >= 'a' && b <= 'z') || (a >= '0' && a <= '9' && b >= '0' && b <= '9'))
+	}
+	for _, delta := range []rune{1, -1} {
+		run := 1
+		for i := 1; i < len(chars); i++ {
+			if sequential(chars[i-1], chars[i], delta) {
+				run++
+			} else {
+				run = 1
+			}
+			longest = max(longest, run)
+		}
+	}
+	return longest
+}
@@ -0,0 +1,83 @@
+package gotpasswd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// KindWindow limits each kind to appear at most Max times in any Size consecutive characters.
+type KindWindow struct {
+	Size int
+	Max  int
+}
+
+// ParseKindWindow parses "SIZE:MAX".
+func ParseKindWindow(s string) (*KindWindow, error) {
+	pair := strings.SplitN(s, ":", 2)
+	if len(pair) != 2 {
+		return nil, errors.New(fmt.Sprintf("Invalid kind window, must be SIZE:MAX: %s", s))
+	}
+	size, err := strconv.Atoi(pair[0])
+	if err != nil || size <= 0 {
+		return nil, errors.New(fmt.Sprintf("Window size must be positive: %s", s))
+	}
+	max, err := strconv.Atoi(pair[1])
+	if err != nil || max <= 0 {
+		return nil, errors.New(fmt.Sprintf("Max number of each kind in a window must be positive: %s", s))
+	}
+	return &KindWindow{Size: size, Max: max}, nil
+}
+
+// Feasible reports whether passwords of the kinds can satisfy the window.
+func (self *KindWindow) Feasible(numKinds int) bool {
+	return numKinds*self.Max >= self.Size
+}
+
+// Satisfied reports whether no kind appears more than Max times in any window of chars.
+func (self *KindWindow) Satisfied(chars []rune) bool {
+	for i := range chars {
+		if !self.allows(chars[max(0, i-self.Size+1):i], chars[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// allows reports whether c can follow window, the preceding characters of at most Size-1.
+func (self *KindWindow) allows(window []rune, c rune) bool {
+	kind, ok := KindOf(c)
+	if !ok {
+		return true
+	}
+	count := 1
+	for _, r := range window {
+		if k, ok := KindOf(r); ok && k == kind {
+			count++
+		}
+	}
+	return count <= self.Max
+}
+
+// randomRunesInWindow draws characters one by one from those the window allows.
+// It never gets stuck when the window is Feasible with kinds of charCandidates.
+func randomRunesInWindow(r io.Reader, charCandidates []rune, length int, window *KindWindow) ([]rune, error) {
+	chars := make([]rune, 0, length)
+	for len(chars) < length {
+		preceding := chars[max(0, len(chars)-window.Size+1):]
+		allowed := filterRunes(charCandidates, func(c rune) bool {
+			return window.allows(preceding, c)
+		})
+		if len(allowed) == 0 {
+			return nil, errors.New("Internal error, no characters are allowed in the kind window")
+		}
+		charIndex, err := randomInt(r, len(allowed))
+		if err != nil {
+			return nil, err
+		}
+		chars = append(chars, allowed[charIndex])
+	}
+	return chars, nil
+}
@@ -0,0 +1,44 @@
+package gotpasswd
+
+import "testing"
+
+func TestKindWindow(t *testing.T) {
+	window, err := ParseKindWindow("4:2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &Config{Kinds: []CharacterKind{ALPHABET, NUMBER}, Length: 32, KindWindow: window}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		passwd, err := Generate(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chars := []rune(passwd)
+		for start := 0; start+window.Size <= len(chars); start++ {
+			counts := make(map[CharacterKind]int)
+			for _, r := range chars[start : start+window.Size] {
+				kind, _ := KindOf(r)
+				if counts[kind]++; counts[kind] > window.Max {
+					t.Fatalf("%q has %d %s characters in the window at %d", passwd, counts[kind], kind, start)
+				}
+			}
+		}
+	}
+	if window.Satisfied([]rune("ab1cde")) {
+		t.Error("3 lower cases in cde are accepted")
+	}
+
+	// digits alone cannot fill 3 characters by 2 of each kind
+	config = &Config{Kinds: []CharacterKind{NUMBER}, Length: 8, KindWindow: &KindWindow{Size: 3, Max: 2}}
+	if err := config.Validate(); err == nil {
+		t.Error("an infeasible window is accepted")
+	}
+	for _, s := range []string{"4", "0:1", "4:0", "x:1"} {
+		if _, err := ParseKindWindow(s); err == nil {
+			t.Errorf("ParseKindWindow(%q) must fail", s)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package gotpasswd
+
+// Keys of keyboard layouts, including the characters typed with shift.
+// Restricting passwords to them makes typing fast, but the smaller pool
+// significantly reduces the entropy per character.
+var layouts = map[string]string{
+	"qwerty-homerow": "asdfghjkl;'" + "ASDFGHJKL:\"",
+	"dvorak-homerow": "aoeuidhtns-" + "AOEUIDHTNS_",
+}
+
+// Characters typeable without the shift key on a US keyboard, lower case letters,
+// digits, space and the unshifted symbols. It reduces the entropy per character too.
+const unshiftedKeys = "abcdefghijklmnopqrstuvwxyz" + "0123456789" + " `-=[]\\;',./"
@@ -0,0 +1,56 @@
+package gotpasswd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNoShift(t *testing.T) {
+	shifted := `ABCDEFGHIJKLMNOPQRSTUVWXYZ~!@#$%^&*()_+{}|:"<>?`
+	config := &Config{Kinds: []CharacterKind{ALPHABET, NUMBER, SYMBOL, UNDERSCORE, SPACE}, Length: 64, NoShift: true}
+	if candidates := string(Candidates(config)); strings.ContainsAny(candidates, shifted) {
+		t.Fatalf("candidates %q contain shifted characters", candidates)
+	}
+	for i := 0; i < 50; i++ {
+		passwd, err := Generate(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i := strings.IndexAny(passwd, shifted); i >= 0 {
+			t.Fatalf("%q contains the shifted character %q", passwd, passwd[i])
+		}
+	}
+	// only lower cases are left of alphabets
+	if candidates := Candidates(&Config{Kinds: []CharacterKind{UPPER}, NoShift: true}); len(candidates) != 0 {
+		t.Errorf("upper cases %q are left", string(candidates))
+	}
+}
+
+func TestLayout(t *testing.T) {
+	for name, keys := range layouts {
+		config := &Config{Kinds: []CharacterKind{ALPHABET, NUMBER, SYMBOL, UNDERSCORE}, ExtraChars: []rune("€"), Length: 32, Layout: name}
+		if err := config.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 50; i++ {
+			passwd, err := Generate(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range passwd {
+				if !strings.ContainsRune(keys, r) {
+					t.Fatalf("%q contains %q out of the keys of %s", passwd, r, name)
+				}
+			}
+		}
+	}
+	// no numbers are on the home rows
+	config := &Config{Kinds: []CharacterKind{NUMBER}, Length: 8, Layout: "qwerty-homerow"}
+	if err := config.Validate(); err == nil {
+		t.Error("numbers must be out of the layout")
+	}
+	config = &Config{Kinds: []CharacterKind{ALPHABET}, Length: 8, Layout: "azerty"}
+	if err := config.Validate(); err == nil {
+		t.Error("unknown layout must fail")
+	}
+}
@@ -0,0 +1,104 @@
+package gotpasswd
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Letters commonly substituted by l33t characters
+var leetSubstitutions = map[rune]string{
+	'0': "o",
+	'1': "il",
+	'2': "z",
+	'3': "e",
+	'4': "a",
+	'5': "s",
+	'6': "g",
+	'7': "t",
+	'8': "b",
+	'9': "g",
+	'@': "a",
+	'$': "s",
+	'+': "t",
+	'|': "il",
+	'!': "i",
+}
+
+// Weak words often disguised by l33t substitutions, in addition to the passphrase words
+var weakWords = []string{
+	"admin", "baseball", "dragon", "football", "iloveyou", "letmein", "login", "master",
+	"monkey", "password", "princess", "qwerty", "secret", "shadow", "sunshine", "superman", "welcome",
+}
+
+// leetMatches reports whether chars spell the word, ignoring cases and l33t substitutions.
+func leetMatches(chars []rune, word string) bool {
+	letters := []rune(word)
+	if len(letters) != len(chars) {
+		return false
+	}
+	for i, w := range letters {
+		c := unicode.ToLower(chars[i])
+		if c == w {
+			continue
+		}
+		if letters, ok := leetSubstitutions[c]; !ok || !strings.ContainsRune(letters, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// leetWord reports whether s, reversing l33t substitutions, is a known word.
+func leetWord(s string) bool {
+	chars := []rune(s)
+	for _, word := range weakWords {
+		if leetMatches(chars, word) {
+			return true
+		}
+	}
+	for _, word := range wordlist {
+		if leetMatches(chars, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// Shortest dictionary words rejected by Config.NoDictWords, shorter ones appear by chance too often
+const minDictWordLength = 4
+
+// dictWord returns a dictionary word embedded in chars, ignoring cases and l33t substitutions, or empty.
+func dictWord(chars []rune, words []string) string {
+	for _, word := range words {
+		n := len([]rune(word))
+		if n < minDictWordLength {
+			continue
+		}
+		for i := 0; i+n <= len(chars); i++ {
+			if leetMatches(chars[i:i+n], strings.ToLower(word)) {
+				return word
+			}
+		}
+	}
+	return ""
+}
+
+// DictionaryRank returns the rank of the word in the known words for strength estimation, 0 if unknown.
+// Weak words rank by their order, and passphrase words, which have no frequencies, rank in the middle of them.
+func DictionaryRank(word string) int {
+	for i, weak := range weakWords {
+		if word == weak {
+			return i + 1
+		}
+	}
+	if index := sort.SearchStrings(wordlist, word); index < len(wordlist) && wordlist[index] == word {
+		return len(weakWords) + len(wordlist)/2
+	}
+	return 0
+}
+
+// LeetLetters returns letters which the l33t character r substitutes, empty if it's not a l33t character.
+func LeetLetters(r rune) string {
+	return leetSubstitutions[r]
+}
@@ -0,0 +1,26 @@
+package gotpasswd
+
+import "testing"
+
+func TestNoLeetWords(t *testing.T) {
+	config := &Config{Kinds: []CharacterKind{ALPHABET, NUMBER, SYMBOL}, Length: 8, NoLeetWords: true}
+	for _, passwd := range []string{"P@55w0rd", "l3tm31n", "DRAGON", "$unsh1ne"} {
+		if reason := violation(config, []rune(passwd)); reason == "" {
+			t.Errorf("%q is accepted", passwd)
+		}
+	}
+	for _, passwd := range []string{"Xq7#kLm2", "p@55w0rdx"} {
+		if reason := violation(config, []rune(passwd)); reason != "" {
+			t.Errorf("%q is rejected for %s", passwd, reason)
+		}
+	}
+
+	// every password of the positions spells password
+	config.Positions = map[int][]rune{
+		0: []rune("pP"), 1: []rune("a@4"), 2: []rune("s$5"), 3: []rune("s$5"),
+		4: []rune("wW"), 5: []rune("o0"), 6: []rune("rR"), 7: []rune("dD"),
+	}
+	if passwd, err := Generate(config); err == nil {
+		t.Errorf("%q is generated", passwd)
+	}
+}
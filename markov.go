@@ -0,0 +1,187 @@
+package gotpasswd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MarkovModel is a character n-gram model trained from words, sampling strings which look like them.
+type MarkovModel struct {
+	// Number of preceding characters each character depends on
+	Order int
+
+	transitions map[string]*markovTransition
+
+	mu      sync.Mutex
+	entropy map[int]float64
+}
+
+// markovTransition counts characters following a context, and words ending after it.
+type markovTransition struct {
+	chars  []rune
+	counts []int
+	ends   int
+	total  int
+}
+
+// TrainMarkov builds a model of the order from words.
+func TrainMarkov(words []string, order int) (*MarkovModel, error) {
+	if order <= 0 {
+		return nil, errors.New("Order of markov model must be positive")
+	}
+	counts := make(map[string]map[rune]int)
+	ends := make(map[string]int)
+	for _, word := range words {
+		chars := []rune(word)
+		if len(chars) == 0 {
+			continue
+		}
+		for i := 0; i <= len(chars); i++ {
+			context := string(chars[max(0, i-order):i])
+			if i == len(chars) {
+				ends[context]++
+				continue
+			}
+			if counts[context] == nil {
+				counts[context] = make(map[rune]int)
+			}
+			counts[context][chars[i]]++
+		}
+	}
+	if len(counts) == 0 {
+		return nil, errors.New("No words to train markov model")
+	}
+
+	model := &MarkovModel{
+		Order:       order,
+		transitions: make(map[string]*markovTransition),
+		entropy:     make(map[int]float64),
+	}
+	contexts := make(map[string]bool)
+	for context := range counts {
+		contexts[context] = true
+	}
+	for context := range ends {
+		contexts[context] = true
+	}
+	for context := range contexts {
+		transition := &markovTransition{ends: ends[context], total: ends[context]}
+		// sorted to sample reproducibly from seeds
+		for r := range counts[context] {
+			transition.chars = append(transition.chars, r)
+		}
+		sort.Slice(transition.chars, func(i, j int) bool { return transition.chars[i] < transition.chars[j] })
+		for _, r := range transition.chars {
+			transition.counts = append(transition.counts, counts[context][r])
+			transition.total += counts[context][r]
+		}
+		model.transitions[context] = transition
+	}
+	return model, nil
+}
+
+// LoadMarkovModel trains a model from a wordlist of passphrases by name, or a file of words separated by spaces.
+func LoadMarkovModel(source string, order int) (*MarkovModel, error) {
+	if words, ok := wordlists[source]; ok {
+		return TrainMarkov(words, order)
+	}
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return nil, err
+	}
+	model, err := TrainMarkov(strings.Fields(string(content)), order)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("%s: %s", source, err))
+	}
+	return model, nil
+}
+
+// next returns the context after r follows context.
+func (self *MarkovModel) next(context string, r rune) string {
+	chars := append([]rune(context), r)
+	return string(chars[max(0, len(chars)-self.Order):])
+}
+
+// generate samples characters word by word, starting another word when one ends before the length.
+func (self *MarkovModel) generate(r io.Reader, length int) ([]rune, error) {
+	buf := make([]rune, 0, length)
+	context := ""
+	for len(buf) < length {
+		transition := self.transitions[context]
+		v, err := randomInt(r, transition.total)
+		if err != nil {
+			return nil, err
+		}
+		if v < transition.ends {
+			context = ""
+			continue
+		}
+		v -= transition.ends
+		for i, count := range transition.counts {
+			if v < count {
+				buf = append(buf, transition.chars[i])
+				context = self.next(context, transition.chars[i])
+				break
+			}
+			v -= count
+		}
+	}
+	return buf, nil
+}
+
+// Entropy returns the Shannon entropy in bits of strings of the length sampled from the model.
+// The end of a word and the first character of the next one are a single choice, so a character is drawn in every step.
+// Different choices may spell the same string, so it's an approximation rather than a bound.
+func (self *MarkovModel) Entropy(length int) float64 {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	if v, ok := self.entropy[length]; ok {
+		return v
+	}
+
+	start := self.transitions[""]
+	type choice struct {
+		prob    float64
+		context string
+	}
+	choices := make(map[string][]choice)
+	choicesOf := func(context string) []choice {
+		if v, ok := choices[context]; ok {
+			return v
+		}
+		list := make([]choice, 0)
+		transition := self.transitions[context]
+		for i, r := range transition.chars {
+			list = append(list, choice{float64(transition.counts[i]) / float64(transition.total), self.next(context, r)})
+		}
+		if transition.ends > 0 {
+			end := float64(transition.ends) / float64(transition.total)
+			for i, r := range start.chars {
+				list = append(list, choice{end * float64(start.counts[i]) / float64(start.total), self.next("", r)})
+			}
+		}
+		choices[context] = list
+		return list
+	}
+
+	entropy := 0.0
+	probs := map[string]float64{"": 1}
+	for step := 0; step < length; step++ {
+		next := make(map[string]float64)
+		for context, p := range probs {
+			for _, c := range choicesOf(context) {
+				entropy -= p * c.prob * math.Log2(c.prob)
+				next[c.context] += p * c.prob
+			}
+		}
+		probs = next
+	}
+	self.entropy[length] = entropy
+	return entropy
+}
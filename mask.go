@@ -0,0 +1,48 @@
+package gotpasswd
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Charsets of hashcat mask placeholders
+var maskCharsets = map[rune]string{
+	'l': "abcdefghijklmnopqrstuvwxyz",
+	'u': "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	'd': "0123456789",
+	'h': "0123456789abcdef",
+	'H': "0123456789ABCDEF",
+	's': " !\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~",
+}
+
+func init() {
+	maskCharsets['a'] = maskCharsets['l'] + maskCharsets['u'] + maskCharsets['d'] + maskCharsets['s']
+}
+
+// ParseMask parses a hashcat style mask such as "?u?l?l?l?d?d-?s" into a Grammar.
+// Placeholders are ?l, ?u, ?d, ?h, ?H, ?s and ?a, "??" is a literal '?' and other characters pass through.
+func ParseMask(s string) (Grammar, error) {
+	src := []rune(s)
+	grammar := make(Grammar, 0, len(src))
+	for i := 0; i < len(src); i++ {
+		chars := []rune{src[i]}
+		if src[i] == '?' {
+			if i+1 >= len(src) {
+				return nil, errors.New(fmt.Sprintf("Unterminated placeholder at %d in mask", i))
+			}
+			i++
+			if src[i] != '?' {
+				charset, ok := maskCharsets[src[i]]
+				if !ok {
+					return nil, errors.New(fmt.Sprintf("Unknown placeholder ?%c at %d in mask", src[i], i-1))
+				}
+				chars = []rune(charset)
+			}
+		}
+		grammar = append(grammar, GrammarTerm{Chars: chars, Min: 1, Max: 1})
+	}
+	if len(grammar) == 0 {
+		return nil, errors.New("Mask must not be empty")
+	}
+	return grammar, nil
+}
@@ -0,0 +1,119 @@
+package gotpasswd
+
+import (
+	"errors"
+	"io"
+	"math"
+)
+
+// randomRunesWithoutRepeat draws characters without replacement, or only without the previous one if adjacent,
+// so every password without repeats is chosen with the equal probability.
+func randomRunesWithoutRepeat(r io.Reader, charCandidates []rune, length int, adjacent bool) ([]rune, error) {
+	pool := append([]rune(nil), charCandidates...)
+	chars := make([]rune, 0, length)
+	for len(chars) < length {
+		allowed := pool
+		if adjacent && len(chars) > 0 {
+			prev := chars[len(chars)-1]
+			allowed = filterRunes(pool, func(c rune) bool { return c != prev })
+		}
+		if len(allowed) == 0 {
+			return nil, errors.New("Internal error, no characters are left to avoid repeats")
+		}
+		charIndex, err := randomInt(r, len(allowed))
+		if err != nil {
+			return nil, err
+		}
+		ch := allowed[charIndex]
+		chars = append(chars, ch)
+		if !adjacent {
+			pool = filterRunes(pool, func(c rune) bool { return c != ch })
+		}
+	}
+	return chars, nil
+}
+
+// noRepeatEntropy is the entropy of length characters from n candidates without repeats, or without adjacent repeats.
+func noRepeatEntropy(n, length int, adjacent bool) float64 {
+	if length <= 0 {
+		return 0
+	}
+	if adjacent {
+		return math.Log2(float64(n)) + float64(length-1)*math.Log2(float64(n-1))
+	}
+	entropy := 0.0
+	for i := 0; i < length; i++ {
+		entropy += math.Log2(float64(n - i))
+	}
+	return entropy
+}
+
+func hasRepeat(chars []rune) bool {
+	seen := make(map[rune]bool)
+	for _, r := range chars {
+		if seen[r] {
+			return true
+		}
+		seen[r] = true
+	}
+	return false
+}
+
+func hasAdjacentRepeat(chars []rune) bool {
+	for i := 0; i+1 < len(chars); i++ {
+		if chars[i] == chars[i+1] {
+			return true
+		}
+	}
+	return false
+}
+
+// randomRunesWithoutRepeatedBigrams draws each character from ones not completing a bigram drawn before.
+// Every character has a bigram with each candidate, so a walk of bigrams can only get stuck at its first character,
+// closing a circuit. Then the circuit is rotated to end at a character with unused bigrams, like Hierholzer's algorithm,
+// so any length up to n*n+1 characters is reached without retries.
+func randomRunesWithoutRepeatedBigrams(r io.Reader, charCandidates []rune, length int) ([]rune, error) {
+	chars := make([]rune, 0, length)
+	if length <= 0 {
+		return chars, nil
+	}
+	used := make(map[[2]rune]bool)
+	unused := func(prev rune) []rune {
+		return filterRunes(charCandidates, func(c rune) bool { return !used[[2]rune{prev, c}] })
+	}
+	first, err := randomInt(r, len(charCandidates))
+	if err != nil {
+		return nil, err
+	}
+	chars = append(chars, charCandidates[first])
+	for len(chars) < length {
+		prev := chars[len(chars)-1]
+		allowed := unused(prev)
+		if len(allowed) == 0 {
+			ends := make([]int, 0)
+			for i := 1; i < len(chars); i++ {
+				if len(unused(chars[i])) > 0 {
+					ends = append(ends, i)
+				}
+			}
+			if len(ends) == 0 {
+				return nil, errors.New("Internal error, no bigrams are left to avoid repeats")
+			}
+			index, err := randomInt(r, len(ends))
+			if err != nil {
+				return nil, err
+			}
+			// chars[0] == chars[len(chars)-1] of the circuit, so bigrams are kept by the rotation
+			end := ends[index]
+			chars = append(append(make([]rune, 0, length), chars[end:]...), chars[1:end+1]...)
+			continue
+		}
+		charIndex, err := randomInt(r, len(allowed))
+		if err != nil {
+			return nil, err
+		}
+		used[[2]rune{prev, allowed[charIndex]}] = true
+		chars = append(chars, allowed[charIndex])
+	}
+	return chars, nil
+}
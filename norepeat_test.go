@@ -0,0 +1,29 @@
+package gotpasswd
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestNoRepeatedBigrams(t *testing.T) {
+	kinds := []CharacterKind{NUMBER}
+	// up to all 100 bigrams of digits
+	for _, length := range []int{2, 10, 60, 101} {
+		config := &Config{Kinds: kinds, Length: length, NoRepeatedBigrams: true}
+		if err := config.Validate(); err != nil {
+			t.Fatalf("length %d: %s", length, err)
+		}
+		for i := 0; i < 20; i++ {
+			passwd, err := Generate(config)
+			if err != nil {
+				t.Fatalf("length %d: %s", length, err)
+			}
+			if utf8.RuneCountInString(passwd) != length || hasRepeatedBigram([]rune(passwd)) {
+				t.Fatalf("length %d: %q repeats a bigram", length, passwd)
+			}
+		}
+	}
+	if err := (&Config{Kinds: kinds, Length: 102, NoRepeatedBigrams: true}).Validate(); err == nil {
+		t.Error("length over the number of bigrams is accepted")
+	}
+}
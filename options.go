@@ -0,0 +1,108 @@
+package gotpasswd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+)
+
+// Option configures a Generator created by New.
+type Option func(*Generator)
+
+// New returns a Generator of alphabets, numbers, symbols, underscores and spaces with the length of 8,
+// configured by opts, or an error if the resulting config is invalid.
+func New(opts ...Option) (*Generator, error) {
+	generator := &Generator{
+		config: &Config{
+			Kinds:  []CharacterKind{ALPHABET, NUMBER, SYMBOL, UNDERSCORE, SPACE},
+			Length: 8,
+			Num:    1,
+		},
+	}
+	for _, opt := range opts {
+		opt(generator)
+	}
+	if err := generator.config.Validate(); err != nil {
+		return nil, err
+	}
+	return generator, nil
+}
+
+// WithLength sets the length of passwords.
+func WithLength(length int) Option {
+	return func(self *Generator) {
+		self.config.Length = length
+		self.config.AllowedLengths = nil
+	}
+}
+
+// WithKinds sets the character kinds of passwords.
+func WithKinds(kinds ...CharacterKind) Option {
+	return func(self *Generator) {
+		self.config.Kinds = append([]CharacterKind(nil), kinds...)
+	}
+}
+
+// WithExclusions removes the characters from candidates of passwords.
+func WithExclusions(chars string) Option {
+	return func(self *Generator) {
+		self.config.Exclude = append(self.config.Exclude, []rune(chars)...)
+	}
+}
+
+// WithNoRepeat lets each character appear at most once in passwords.
+func WithNoRepeat() Option {
+	return func(self *Generator) {
+		self.config.NoRepeat = true
+	}
+}
+
+// WithNoAdjacentRepeat forbids the same character twice in a row in passwords.
+func WithNoAdjacentRepeat() Option {
+	return func(self *Generator) {
+		self.config.NoAdjacentRepeat = true
+	}
+}
+
+// WithRand sets the source of randomness, crypto/rand.Reader by default.
+func WithRand(r io.Reader) Option {
+	return func(self *Generator) {
+		self.rand = r
+	}
+}
+
+// WithConfig replaces the whole config, for settings without dedicated options.
+// Options following it modify a copy of config.
+func WithConfig(config *Config) Option {
+	return func(self *Generator) {
+		copied := *config
+		self.config = &copied
+	}
+}
+
+// Generate generates a password with the config given to New.
+func (self *Generator) Generate() (Password, error) {
+	return self.GenerateContext(context.Background())
+}
+
+// GenerateContext is same as Generate, but gives up regenerations when ctx is done.
+func (self *Generator) GenerateContext(ctx context.Context) (Password, error) {
+	if self.config == nil {
+		return Password{}, errors.New("Generator has no config, create it by New")
+	}
+	return self.GeneratePasswordContext(ctx, self.config)
+}
+
+// Stream generates passwords one by one with the config given to New, until ctx is done or an error occurs.
+// Passwords are not buffered, and an error is yielded as the last element.
+func (self *Generator) Stream(ctx context.Context) iter.Seq2[Password, error] {
+	return func(yield func(Password, error) bool) {
+		for {
+			passwd, err := self.GenerateContext(ctx)
+			if !yield(passwd, err) || err != nil {
+				return
+			}
+		}
+	}
+}
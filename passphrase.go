@@ -0,0 +1,201 @@
+package gotpasswd
+
+import (
+	"bufio"
+	_ "embed"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"unicode"
+)
+
+//go:embed wordlist.txt
+var embeddedWordlist string
+
+//go:embed wordlist_ja.txt
+var embeddedJapaneseWordlist string
+
+// The EFF diceware wordlists of rolls and words, by the Electronic Frontier Foundation under CC BY 3.0 US,
+// see https://www.eff.org/deeplinks/2016/07/new-wordlists-random-passphrases
+//
+//go:embed eff_long.txt
+var embeddedEFFLongWordlist string
+
+//go:embed eff_short.txt
+var embeddedEFFShortWordlist string
+
+// Words for passphrases, common english words without offensive ones
+var wordlist = strings.Fields(embeddedWordlist)
+
+// Wordlists for Config.Wordlist, the japanese one is of common nouns in hiragana,
+// eff-long is of 5 dice and eff-short is of 4 dice
+var wordlists = map[string][]string{
+	"english":   wordlist,
+	"japanese":  strings.Fields(embeddedJapaneseWordlist),
+	"eff-long":  diceWords(embeddedEFFLongWordlist),
+	"eff-short": diceWords(embeddedEFFShortWordlist),
+}
+
+// diceWords returns words of lines of a roll and a word separated by a tab.
+func diceWords(s string) []string {
+	words := make([]string, 0)
+	for _, line := range strings.Split(strings.TrimSuffix(s, "\n"), "\n") {
+		_, word, _ := strings.Cut(line, "\t")
+		words = append(words, word)
+	}
+	return words
+}
+
+const passphraseSeparator = "-"
+
+// Capitalizations of words of passphrases, random capitalizes the first letter of each word by 1/2
+var capitalizations = map[string]bool{
+	"none":   true,
+	"first":  true,
+	"random": true,
+	"all":    true,
+}
+
+func (self *Config) separator() string {
+	if self.Separator != nil {
+		return *self.Separator
+	}
+	return passphraseSeparator
+}
+
+func capitalizeFirst(word string) string {
+	chars := []rune(word)
+	chars[0] = unicode.ToUpper(chars[0])
+	return string(chars)
+}
+
+// PassphraseWords returns words for passphrases, excluding flagged ones.
+func PassphraseWords(config *Config) []string {
+	candidates := wordlist
+	if config.CustomWordlist != nil {
+		candidates = config.CustomWordlist
+	} else if config.Wordlist != "" {
+		candidates = wordlists[config.Wordlist]
+	}
+	if len(config.Blocklist) == 0 {
+		return candidates
+	}
+	words := make([]string, 0, len(candidates))
+	for _, word := range candidates {
+		if !containsBlocked(word, config.Blocklist) {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// LoadWordlist reads words for passphrases from the file, one per line, dropping duplicated ones.
+func LoadWordlist(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	words := make([]string, 0)
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if word := strings.TrimSpace(scanner.Text()); word != "" && !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	return words, scanner.Err()
+}
+
+// IsWordlist returns whether the name is of an embedded wordlist.
+func IsWordlist(name string) bool {
+	_, ok := wordlists[name]
+	return ok
+}
+
+func generatePassphrase(r io.Reader, config *Config) ([]rune, error) {
+	candidates := PassphraseWords(config)
+	words := make([]string, config.Words)
+	for i := range words {
+		index, err := randomInt(r, len(candidates))
+		if err != nil {
+			return nil, err
+		}
+		words[i] = candidates[index]
+		switch config.Capitalize {
+		case "first":
+			words[i] = capitalizeFirst(words[i])
+		case "random":
+			v, err := randomInt(r, 2)
+			if err != nil {
+				return nil, err
+			}
+			if v == 0 {
+				words[i] = capitalizeFirst(words[i])
+			}
+		case "all":
+			words[i] = strings.ToUpper(words[i])
+		}
+	}
+	separator := config.separator()
+	passphrase := strings.Join(words, separator)
+	if config.PinSuffix > 0 {
+		pin, err := randomRunes(r, dict[NUMBER], config.PinSuffix)
+		if err != nil {
+			return nil, err
+		}
+		passphrase += separator + string(pin)
+	}
+	chars := []rune(passphrase)
+	for _, inject := range []struct {
+		chars []rune
+		count int
+	}{{dict[NUMBER], config.InjectDigits}, {dict[SYMBOL], config.InjectSymbols}} {
+		for i := 0; i < inject.count; i++ {
+			c, err := randomRunes(r, inject.chars, 1)
+			if err != nil {
+				return nil, err
+			}
+			index, err := randomInt(r, len(chars)+1)
+			if err != nil {
+				return nil, err
+			}
+			chars = append(chars[:index], append(c, chars[index:]...)...)
+		}
+	}
+	return chars, nil
+}
+
+// passphraseEntropy returns the entropy of passphrases by config. Injected characters are counted with
+// their positions, which is an approximation since different positions may spell the same passphrase.
+func passphraseEntropy(config *Config) float64 {
+	entropy := float64(config.Words)*math.Log2(float64(len(PassphraseWords(config)))) + float64(config.PinSuffix)*math.Log2(float64(len(dict[NUMBER])))
+	if config.Capitalize == "random" {
+		// capitalizing caseless words spells the same passphrase
+		words := PassphraseWords(config)
+		casable := 0
+		for _, word := range words {
+			if capitalizeFirst(word) != word {
+				casable++
+			}
+		}
+		entropy += float64(config.Words) * float64(casable) / float64(len(words))
+	}
+	// positions are counted by the shortest passphrase
+	length := config.Words
+	if config.PinSuffix > 0 {
+		length += config.PinSuffix
+	}
+	for i := 0; i < config.InjectDigits; i++ {
+		entropy += math.Log2(float64(len(dict[NUMBER]))) + math.Log2(float64(length+1))
+		length++
+	}
+	for i := 0; i < config.InjectSymbols; i++ {
+		entropy += math.Log2(float64(len(dict[SYMBOL]))) + math.Log2(float64(length+1))
+		length++
+	}
+	return entropy
+}
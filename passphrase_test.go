@@ -0,0 +1,108 @@
+package gotpasswd
+
+import (
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPassphraseEntropyOfRandomCapitalization(t *testing.T) {
+	words := float64(len(wordlists["japanese"]))
+	config := &Config{Words: 4, Wordlist: "japanese", Capitalize: "random"}
+	// hiragana have no cases
+	if entropy, expected := Entropy(config), 4*math.Log2(words); math.Abs(entropy-expected) > 1e-9 {
+		t.Errorf("entropy of caseless words is %f bits, expected %f bits", entropy, expected)
+	}
+
+	config = &Config{Words: 4, CustomWordlist: []string{"apple", "banana", "123", "456"}, Capitalize: "random"}
+	if entropy, expected := Entropy(config), 4*2+4*0.5; math.Abs(entropy-expected) > 1e-9 {
+		t.Errorf("entropy of half casable words is %f bits, expected %f bits", entropy, expected)
+	}
+}
+
+func TestPassphraseRejectsEmptyWords(t *testing.T) {
+	config := &Config{Length: 8, Words: 3, CustomWordlist: []string{"apple", "banana"}, Capitalize: "first"}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	config.CustomWordlist = append(config.CustomWordlist, "")
+	if err := config.Validate(); err == nil {
+		t.Error("empty words are accepted")
+	}
+}
+
+func TestPinSuffix(t *testing.T) {
+	config := &Config{Length: 8, Words: 3, PinSuffix: 4}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	words := PassphraseWords(config)
+	if entropy, expected := Entropy(config), 3*math.Log2(float64(len(words)))+4*math.Log2(10); math.Abs(entropy-expected) > 1e-9 {
+		t.Errorf("entropy is %f bits, expected %f bits of words and digits", entropy, expected)
+	}
+	pin := regexp.MustCompile(`^[0-9]{4}$`)
+	for i := 0; i < 50; i++ {
+		passwd, err := Generate(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts := strings.Split(passwd, "-")
+		if len(parts) != 4 || !pin.MatchString(parts[3]) {
+			t.Fatalf("%q is not 3 words and 4 digits", passwd)
+		}
+		for _, word := range parts[:3] {
+			if !slices.Contains(words, word) {
+				t.Fatalf("%q of %q is not a word", word, passwd)
+			}
+		}
+	}
+}
+
+func TestEFFWordlists(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		wordlist string
+		dice     int
+		words    int
+	}{
+		{"eff-long", embeddedEFFLongWordlist, 5, 7776},
+		{"eff-short", embeddedEFFShortWordlist, 4, 1296},
+	} {
+		words := wordlists[c.name]
+		if len(words) != c.words {
+			t.Fatalf("%s has %d words, want %d", c.name, len(words), c.words)
+		}
+		seen := make(map[string]bool)
+		for i, line := range strings.Split(strings.TrimSuffix(c.wordlist, "\n"), "\n") {
+			// rolls are numbers of the base 6 of digits 1 to 6, in order
+			roll := make([]byte, c.dice)
+			for j, n := c.dice-1, i; j >= 0; j, n = j-1, n/6 {
+				roll[j] = byte('1' + n%6)
+			}
+			if want := string(roll) + "\t" + words[i]; line != want || words[i] == "" || seen[words[i]] {
+				t.Fatalf("%s: line %d is %q, want %q of a unique word", c.name, i+1, line, want)
+			}
+			seen[words[i]] = true
+		}
+		// a few words have hyphens, such as t-shirt
+		separator := " "
+		config := &Config{Length: 8, Words: 6, Wordlist: c.name, Separator: &separator}
+		if err := config.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		if entropy, expected := Entropy(config), 6*math.Log2(float64(c.words)); math.Abs(entropy-expected) > 1e-9 {
+			t.Errorf("%s: entropy is %f bits, expected %f bits", c.name, entropy, expected)
+		}
+		passwd, err := Generate(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, word := range strings.Split(passwd, separator) {
+			if !seen[word] {
+				t.Errorf("%s: %q of %q is not a word", c.name, word, passwd)
+			}
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package gotpasswd
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// Password is a generated password with its metadata.
+type Password struct {
+	Value string
+	// Theoretical entropy in bits
+	Entropy float64
+	// Character kinds appeared in Value
+	Kinds []CharacterKind
+}
+
+type Generator struct {
+	// Source of randomness, crypto/rand.Reader if nil
+	rand io.Reader
+	// Config for Generate, given to New
+	config *Config
+}
+
+// NewGenerator returns a Generator drawing randomness from r, crypto/rand.Reader if nil.
+func NewGenerator(r io.Reader) *Generator {
+	return &Generator{rand: r}
+}
+
+func (self *Generator) GeneratePassword(config *Config) (Password, error) {
+	return self.GeneratePasswordContext(context.Background(), config)
+}
+
+// GeneratePasswordContext is same as GeneratePassword, but gives up regenerations when ctx is done.
+func (self *Generator) GeneratePasswordContext(ctx context.Context, config *Config) (Password, error) {
+	r := self.rand
+	if r == nil {
+		r = rand.Reader
+	}
+	value, length, err := generate(ctx, config, r)
+	if err != nil {
+		return Password{}, err
+	}
+	// Entropy of config is of the minimum of AllowedLengths, the length of the password is not secret
+	lengthConfig := *config
+	lengthConfig.Length = length
+	return Password{
+		Value:   value,
+		Entropy: Entropy(&lengthConfig),
+		Kinds:   KindsOf(value),
+	}, nil
+}
+
+// Entropy returns the theoretical entropy in bits of passwords generated with config,
+// the minimum of ones of AllowedLengths.
+func Entropy(config *Config) float64 {
+	if config.Grammar != nil {
+		return config.Grammar.Entropy()
+	}
+	if config.Pronounceable {
+		return pronounceableEntropy(config.Length)
+	}
+	if config.ID != "" {
+		return identifiers[config.ID]
+	}
+	if config.RawEncoding != "" {
+		return float64(config.Length * 8)
+	}
+	if config.Token != nil {
+		return tokenEntropy(config.Length)
+	}
+	if config.Mnemonic > 0 {
+		// checksum bits are not random
+		return float64(config.Mnemonic * 32 / 3)
+	}
+	if config.Markov != nil {
+		return config.Markov.Entropy(config.Length)
+	}
+	if config.WeightedCharsets != nil {
+		return float64(config.Length) * WeightedEntropy(config.WeightedCharsets)
+	}
+	if config.Words > 0 {
+		return passphraseEntropy(config)
+	}
+	candidates := Candidates(config)
+	entropy := candidatesEntropy(config, candidates, 0)
+	if config.MaxDistinctSymbols > 0 {
+		// symbols are chosen for each password, and characters are drawn from candidates left by the choice.
+		// passwords don't reveal the choice entirely, so it's at most the entropy without the limit.
+		entropy = min(entropy, symbolChoiceEntropy(candidates, config.MaxDistinctSymbols)+candidatesEntropy(config, candidates, config.MaxDistinctSymbols))
+	}
+	return entropy
+}
+
+// candidatesEntropy returns the entropy in bits of characters drawn from candidates, limited to maxSymbols distinct symbols if positive.
+func candidatesEntropy(config *Config, candidates []rune, maxSymbols int) float64 {
+	n := limitedSymbolsCount(candidates, maxSymbols)
+	if (config.NoRepeat || config.NoAdjacentRepeat) && len(config.Positions) == 0 {
+		return noRepeatEntropy(n, config.Length, !config.NoRepeat)
+	}
+	entropy := float64(config.Length-len(config.Positions)) * math.Log2(float64(n))
+	for _, alphabet := range config.Positions {
+		entropy += math.Log2(float64(len(alphabet)))
+	}
+	for index, alphabet := range config.edgeAlphabets(candidates, config.Length) {
+		// alphabets of Positions at the edges are narrowed instead of candidates
+		if position, ok := config.Positions[index]; ok {
+			entropy += math.Log2(float64(len(alphabet))) - math.Log2(float64(len(position)))
+		} else {
+			entropy += math.Log2(float64(limitedSymbolsCount(alphabet, maxSymbols))) - math.Log2(float64(n))
+		}
+	}
+	return entropy
+}
+
+// KindOf returns the character kind which r belongs to.
+func KindOf(r rune) (CharacterKind, bool) {
+	for kind, chars := range dict {
+		for _, c := range chars {
+			if c == r {
+				return kind, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// KindsOf returns the character kinds appeared in s, ordered by kind.
+func KindsOf(s string) []CharacterKind {
+	seen := make(map[CharacterKind]bool)
+	for _, r := range s {
+		if kind, ok := KindOf(r); ok {
+			seen[kind] = true
+		}
+	}
+	kinds := make([]CharacterKind, 0, len(seen))
+	for kind := range seen {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i] < kinds[j] })
+	return kinds
+}
+
+// Longest length and most words tried by FitEntropy
+const maxFitLength = 1024
+
+// FitEntropy sets the minimum length of password, or number of words of passphrases, whose entropy is at least bits.
+func (self *Config) FitEntropy(bits float64) error {
+	if self.Grammar != nil || self.Mnemonic > 0 {
+		return errors.New("Entropy of grammars and mnemonics is fixed, it cannot be fitted")
+	}
+	size := &self.Length
+	if self.Words > 0 {
+		size = &self.Words
+	}
+	for n := 1; n <= maxFitLength; n++ {
+		*size = n
+		if Entropy(self) >= bits {
+			return nil
+		}
+	}
+	return errors.New(fmt.Sprintf("Cannot reach %.1f bits of entropy in %d characters or words", bits, maxFitLength))
+}
@@ -0,0 +1,57 @@
+package gotpasswd
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPasswordEntropy(t *testing.T) {
+	config := &Config{Kinds: []CharacterKind{ALPHABET, NUMBER}, Length: 8, AllowedLengths: []int{8, 16, 24}}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	bits := math.Log2(float64(len(Candidates(config))))
+	if entropy := Entropy(config); math.Abs(entropy-8*bits) > 1e-9 {
+		t.Errorf("Entropy = %f, want %f of the minimum length", entropy, 8*bits)
+	}
+	generator := NewGenerator(nil)
+	for i := 0; i < 50; i++ {
+		passwd, err := generator.GeneratePassword(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := float64(utf8.RuneCountInString(passwd.Value)) * bits; math.Abs(passwd.Entropy-want) > 1e-9 {
+			t.Fatalf("Entropy of %q = %f, want %f of its length", passwd.Value, passwd.Entropy, want)
+		}
+	}
+}
+
+func TestPasswordKinds(t *testing.T) {
+	generator := NewGenerator(nil)
+	passwd, err := generator.GeneratePassword(&Config{Kinds: []CharacterKind{NUMBER}, Length: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(passwd.Kinds, []CharacterKind{NUMBER}) {
+		t.Errorf("Kinds of %q = %v, want number", passwd.Value, passwd.Kinds)
+	}
+
+	config := &Config{Kinds: []CharacterKind{ALPHABET, NUMBER, SYMBOL}, Length: 16}
+	for i := 0; i < 20; i++ {
+		passwd, err := generator.GeneratePassword(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(passwd.Kinds, KindsOf(passwd.Value)) || !slices.IsSorted(passwd.Kinds) {
+			t.Fatalf("Kinds of %q = %v", passwd.Value, passwd.Kinds)
+		}
+		// alphabets are reported as upper and lower cases
+		for _, kind := range passwd.Kinds {
+			if kind == ALPHABET {
+				t.Fatalf("Kinds of %q must not have the alias", passwd.Value)
+			}
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package gotpasswd
+
+// Shortest ascending or descending run of digits rejected as a trivial PIN
+const trivialPinRun = 4
+
+// trivialPin describes why a PIN of digits is trivially guessable, or returns empty.
+// Non-digit characters are not PINs, they're left to other constraints.
+func trivialPin(chars []rune) string {
+	for _, r := range chars {
+		if r < '0' || r > '9' {
+			return ""
+		}
+	}
+	n := len(chars)
+	if n < 2 {
+		return ""
+	}
+	// a repetition of a shorter part, such as 1111, 1212 and 123123
+	for period := 1; period <= n/2; period++ {
+		if n%period != 0 {
+			continue
+		}
+		repeated := true
+		for i := period; i < n && repeated; i++ {
+			repeated = chars[i] == chars[i-period]
+		}
+		if repeated {
+			return "a repeated pattern"
+		}
+	}
+	for _, delta := range []rune{1, -1} {
+		run := 1
+		for i := 1; i < n; i++ {
+			if chars[i]-chars[i-1] == delta {
+				run++
+			} else {
+				run = 1
+			}
+			if run >= min(trivialPinRun, n) {
+				return "an ascending or descending run"
+			}
+		}
+	}
+	// years of birth and such, at the head or the tail
+	if n >= 4 {
+		for _, year := range []string{string(chars[:4]), string(chars[n-4:])} {
+			if year >= "1900" && year <= "2099" {
+				return "a common year"
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package gotpasswd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var positionSpecPattern = regexp.MustCompile(`^(\d+):(.+)$`)
+
+// ParsePositions parses "0:ABCDEF,1:0123456789" into alphabets for each position.
+// An alphabet can contain ',' unless it's followed by another "index:".
+func ParsePositions(s string) (map[int][]rune, error) {
+	specs := make([]string, 0)
+	for _, segment := range strings.Split(s, ",") {
+		if len(specs) > 0 && !positionSpecPattern.MatchString(segment) {
+			specs[len(specs)-1] += "," + segment
+		} else {
+			specs = append(specs, segment)
+		}
+	}
+
+	positions := make(map[int][]rune)
+	for _, spec := range specs {
+		m := positionSpecPattern.FindStringSubmatch(spec)
+		if m == nil {
+			return nil, errors.New(fmt.Sprintf("Invalid position spec, must be index:chars: %s", spec))
+		}
+		index, err := strconv.Atoi(m[1])
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := positions[index]; ok {
+			return nil, errors.New(fmt.Sprintf("Duplicated position: %d", index))
+		}
+		positions[index] = uniqueRunes([]rune(m[2]))
+	}
+	return positions, nil
+}
+
+func uniqueRunes(runes []rune) []rune {
+	seen := make(map[rune]bool)
+	unique := make([]rune, 0, len(runes))
+	for _, r := range runes {
+		if !seen[r] {
+			seen[r] = true
+			unique = append(unique, r)
+		}
+	}
+	return unique
+}
+
+// fillPositions replaces characters at the positions by ones drawn from their alphabets.
+func fillPositions(r io.Reader, positions map[int][]rune, chars []rune) error {
+	for i := range chars {
+		alphabet, ok := positions[i]
+		if !ok {
+			continue
+		}
+		index, err := randomInt(r, len(alphabet))
+		if err != nil {
+			return err
+		}
+		chars[i] = alphabet[index]
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package gotpasswd
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParsePositions(t *testing.T) {
+	for s, want := range map[string]map[int]string{
+		"0:ABC,1:0123": {0: "ABC", 1: "0123"},
+		// commas not followed by index: are of the alphabet
+		"0:a,b,1:c": {0: "a,b", 1: "c"},
+		"0:,,1:x":   {0: ",", 1: "x"},
+		"2:aab":     {2: "ab"},
+		// an alphabet cannot have ",1:" in it, it's always a position
+		"0:a,1:b": {0: "a", 1: "b"},
+	} {
+		positions, err := ParsePositions(s)
+		if err != nil {
+			t.Errorf("ParsePositions(%q) = %v", s, err)
+			continue
+		}
+		if len(positions) != len(want) {
+			t.Errorf("ParsePositions(%q) = %q, want %q", s, positions, want)
+		}
+		for index, alphabet := range want {
+			if string(positions[index]) != alphabet {
+				t.Errorf("ParsePositions(%q)[%d] = %q, want %q", s, index, string(positions[index]), alphabet)
+			}
+		}
+	}
+	for _, s := range []string{"", "a:b", "x,0:a", "0:a,0:b", "-1:a"} {
+		if _, err := ParsePositions(s); err == nil {
+			t.Errorf("ParsePositions(%q) must fail", s)
+		}
+	}
+}
+
+func TestPositionsOutOfLength(t *testing.T) {
+	config := &Config{Kinds: []CharacterKind{ALPHABET}, Length: 8, Positions: map[int][]rune{8: []rune("A")}}
+	if err := config.Validate(); err == nil {
+		t.Error("position 8 must be out of the length 8")
+	}
+	// Length is the minimum of AllowedLengths
+	config = &Config{Kinds: []CharacterKind{ALPHABET}, Length: 8, AllowedLengths: []int{8, 12}, Positions: map[int][]rune{10: []rune("A")}}
+	if err := config.Validate(); err == nil {
+		t.Error("position 10 must be out of the length 8 of the allowed ones")
+	}
+}
+
+func TestPositionsEntropy(t *testing.T) {
+	n := math.Log2(62)
+	for _, c := range []struct {
+		config *Config
+		want   float64
+	}{
+		{&Config{Length: 8, Positions: map[int][]rune{3: []rune("abc")}}, 7*n + math.Log2(3)},
+		{&Config{Length: 8, Positions: map[int][]rune{3: []rune("abc")}, StartWith: []CharacterKind{NUMBER}}, 6*n + math.Log2(3) + math.Log2(10)},
+		// the first character is the only number of the position
+		{&Config{Length: 8, Positions: map[int][]rune{0: []rune("A1")}, StartWith: []CharacterKind{NUMBER}}, 7 * n},
+		{&Config{Length: 8, Positions: map[int][]rune{7: []rune("ab12")}, StartWith: []CharacterKind{NUMBER}, EndWith: []CharacterKind{NUMBER}}, 6*n + math.Log2(10) + 1},
+	} {
+		c.config.Kinds = []CharacterKind{ALPHABET, NUMBER}
+		if err := c.config.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		if entropy := Entropy(c.config); math.Abs(entropy-c.want) > 1e-9 {
+			t.Errorf("Entropy of %v = %f, want %f", c.config.Positions, entropy, c.want)
+		}
+	}
+}
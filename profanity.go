@@ -0,0 +1,48 @@
+package gotpasswd
+
+import (
+	"bufio"
+	_ "embed"
+	"os"
+	"strings"
+)
+
+//go:embed profanity.txt
+var embeddedProfanity string
+
+// Flagged words for Config.Blocklist, matched as case-insensitive substrings.
+// It's best-effort, words out of the list or in other languages are not detected.
+var profanity = strings.Fields(embeddedProfanity)
+
+// Profanity returns the built-in flagged words for Config.Blocklist.
+func Profanity() []string {
+	return append([]string(nil), profanity...)
+}
+
+// LoadBlocklist reads flagged words from the file, one per line.
+func LoadBlocklist(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	blocklist := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if word := strings.ToLower(strings.TrimSpace(scanner.Text())); word != "" {
+			blocklist = append(blocklist, word)
+		}
+	}
+	return blocklist, scanner.Err()
+}
+
+func containsBlocked(s string, blocklist []string) bool {
+	s = strings.ToLower(s)
+	for _, word := range blocklist {
+		if strings.Contains(s, word) {
+			return true
+		}
+	}
+	return false
+}
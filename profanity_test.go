@@ -0,0 +1,55 @@
+package gotpasswd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBlocklist(t *testing.T) {
+	config := &Config{Length: 8, Words: 4, CustomWordlist: []string{"ba", "d", "oo", "zz", "banana"}, Blocklist: []string{"bad", "nan"}}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if words := PassphraseWords(config); !slices.Equal(words, []string{"ba", "d", "oo", "zz"}) {
+		t.Errorf("PassphraseWords = %v, want without banana", words)
+	}
+	for i := 0; i < 100; i++ {
+		passwd, err := Generate(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// flagged across words too
+		if strings.Contains(strings.ReplaceAll(passwd, "-", ""), "bad") {
+			t.Fatalf("%q contains the blocked word", passwd)
+		}
+	}
+	if reason := violation(config, []rune("ba-d-zz-oo")); reason != "flagged words" {
+		t.Errorf("violation = %q, want of flagged words", reason)
+	}
+
+	// cases are ignored
+	config = &Config{Kinds: []CharacterKind{ALPHABET}, Length: 8, Blocklist: []string{"bad"}}
+	if reason := violation(config, []rune("xxBaDxxx")); reason != "flagged words" {
+		t.Errorf("violation = %q, want of flagged words", reason)
+	}
+}
+
+func TestLoadBlocklist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	if err := os.WriteFile(path, []byte("Foo\n\n  bar \n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	blocklist, err := LoadBlocklist(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(blocklist, []string{"foo", "bar"}) {
+		t.Errorf("LoadBlocklist = %q", blocklist)
+	}
+	if len(Profanity()) == 0 {
+		t.Error("the built-in list must not be empty")
+	}
+}
@@ -0,0 +1,85 @@
+package gotpasswd
+
+import "maps"
+
+// Profile is a named set of settings satisfying a password policy.
+type Profile struct {
+	Kinds       []CharacterKind
+	Length      int
+	MinKinds    int
+	NoAmbiguous bool
+	// Longest length accepted by the policy, unlimited if 0
+	MaxLength int
+	MinCounts map[CharacterKind]int
+}
+
+var profiles = map[string]*Profile{
+	// NIST SP 800-63B, long passwords without composition rules
+	"nist": {
+		Kinds:     []CharacterKind{ALPHABET, NUMBER, SYMBOL, UNDERSCORE, SPACE},
+		Length:    15,
+		MaxLength: 64,
+	},
+	// PCI DSS v4.0, both of alphabets and numbers
+	"pci": {
+		Kinds:     []CharacterKind{ALPHABET, NUMBER, SYMBOL, UNDERSCORE},
+		Length:    12,
+		MinCounts: map[CharacterKind]int{ALPHABET: 1, NUMBER: 1},
+	},
+	// Active Directory complexity, 3 of the character categories
+	"windows-ad": {
+		Kinds:    []CharacterKind{ALPHABET, NUMBER, SYMBOL, UNDERSCORE},
+		Length:   14,
+		MinKinds: 3,
+	},
+	// WPA2 passphrases of the longest 63 characters
+	"wifi": {
+		Kinds:  []CharacterKind{ALPHABET, NUMBER, SYMBOL, UNDERSCORE, SPACE},
+		Length: 63,
+	},
+	// WPA2 passphrases typed on routers and TVs, without ambiguous characters
+	"wifi-router": {
+		Kinds:       []CharacterKind{ALPHABET, NUMBER},
+		Length:      20,
+		NoAmbiguous: true,
+	},
+}
+
+// Other names of profiles, not listed by ProfileNames
+var profileAliases = map[string]string{
+	"ad": "windows-ad",
+}
+
+// LookupProfile returns the profile of the name.
+func LookupProfile(name string) (*Profile, bool) {
+	if alias, ok := profileAliases[name]; ok {
+		name = alias
+	}
+	profile, ok := profiles[name]
+	return profile, ok
+}
+
+// ProfileNames returns names of profiles in order.
+func ProfileNames() []string {
+	return sortedKeys(profiles)
+}
+
+// Apply overrides the settings of config by the profile.
+func (self *Profile) Apply(config *Config) {
+	config.Kinds = self.Kinds
+	config.Length = self.Length
+	config.AllowedLengths = nil
+	config.MinKinds = self.MinKinds
+	config.NoAmbiguous = config.NoAmbiguous || self.NoAmbiguous
+	if len(self.MinCounts) > 0 {
+		// config may share the map with others
+		minCounts := maps.Clone(config.MinCounts)
+		if minCounts == nil {
+			minCounts = make(map[CharacterKind]int)
+		}
+		for kind, min := range self.MinCounts {
+			minCounts[kind] = max(minCounts[kind], min)
+		}
+		config.MinCounts = minCounts
+	}
+}
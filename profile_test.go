@@ -0,0 +1,34 @@
+package gotpasswd
+
+import "testing"
+
+func TestProfileApply(t *testing.T) {
+	profile, ok := LookupProfile("pci")
+	if !ok {
+		t.Fatal("pci profile must exist")
+	}
+	minCounts := map[CharacterKind]int{UPPER: 2}
+	config := &Config{MinCounts: minCounts}
+	profile.Apply(config)
+	if config.Length != 12 || config.MinCounts[ALPHABET] != 1 || config.MinCounts[NUMBER] != 1 || config.MinCounts[UPPER] != 2 {
+		t.Errorf("Apply = %+v", config)
+	}
+	if len(minCounts) != 1 {
+		t.Errorf("Apply modified the map of the config: %v", minCounts)
+	}
+	if err := config.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLookupProfileAlias(t *testing.T) {
+	ad, ok := LookupProfile("ad")
+	if windowsAD, _ := LookupProfile("windows-ad"); !ok || ad != windowsAD {
+		t.Error("ad must be the windows-ad profile")
+	}
+	for _, name := range ProfileNames() {
+		if name == "ad" {
+			t.Error("aliases must not be listed")
+		}
+	}
+}
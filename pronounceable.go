@@ -0,0 +1,299 @@
+package gotpasswd
+
+import (
+	"io"
+	"math"
+	"strings"
+	"unicode"
+)
+
+const (
+	phonemeConsonant = 1 << iota
+	phonemeVowel
+	phonemeDipthong
+	phonemeNotFirst
+)
+
+type phoneme struct {
+	chars string
+	flags int
+}
+
+// Phonemes of pwgen
+var phonemes = []phoneme{
+	{"a", phonemeVowel},
+	{"ae", phonemeVowel | phonemeDipthong},
+	{"ah", phonemeVowel | phonemeDipthong},
+	{"ai", phonemeVowel | phonemeDipthong},
+	{"b", phonemeConsonant},
+	{"c", phonemeConsonant},
+	{"ch", phonemeConsonant | phonemeDipthong},
+	{"d", phonemeConsonant},
+	{"e", phonemeVowel},
+	{"ee", phonemeVowel | phonemeDipthong},
+	{"ei", phonemeVowel | phonemeDipthong},
+	{"f", phonemeConsonant},
+	{"g", phonemeConsonant},
+	{"gh", phonemeConsonant | phonemeDipthong | phonemeNotFirst},
+	{"h", phonemeConsonant},
+	{"i", phonemeVowel},
+	{"ie", phonemeVowel | phonemeDipthong},
+	{"j", phonemeConsonant},
+	{"k", phonemeConsonant},
+	{"l", phonemeConsonant},
+	{"m", phonemeConsonant},
+	{"n", phonemeConsonant},
+	{"ng", phonemeConsonant | phonemeDipthong | phonemeNotFirst},
+	{"o", phonemeVowel},
+	{"oh", phonemeVowel | phonemeDipthong},
+	{"oo", phonemeVowel | phonemeDipthong},
+	{"p", phonemeConsonant},
+	{"ph", phonemeConsonant | phonemeDipthong},
+	{"qu", phonemeConsonant | phonemeDipthong},
+	{"r", phonemeConsonant},
+	{"s", phonemeConsonant},
+	{"sh", phonemeConsonant | phonemeDipthong},
+	{"t", phonemeConsonant},
+	{"th", phonemeConsonant | phonemeDipthong},
+	{"u", phonemeVowel},
+	{"v", phonemeConsonant},
+	{"w", phonemeConsonant},
+	{"x", phonemeConsonant},
+	{"y", phonemeConsonant},
+	{"z", phonemeConsonant},
+}
+
+// Symbols of pwgen
+const pronounceableSymbols = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+// pronounceableFeatures returns whether upper cases, digits and symbols are mixed into pronounceable passwords, by Kinds.
+func pronounceableFeatures(config *Config) (uppers, digits, symbols bool) {
+	for _, kind := range config.Kinds {
+		switch kind {
+		case ALPHABET, UPPER:
+			uppers = true
+		case NUMBER:
+			digits = true
+		case SYMBOL:
+			symbols = true
+		}
+	}
+	return
+}
+
+// generatePronounceable is a port of pw_phonemes of pwgen, alternating consonant and vowel phonemes.
+// It mixes at least one of upper cases, digits and symbols in, when they are enabled by Kinds.
+func generatePronounceable(r io.Reader, config *Config, length int) ([]rune, error) {
+	uppers, digits, symbols := pronounceableFeatures(config)
+	chance := func(n, below int) (bool, error) {
+		v, err := randomInt(r, n)
+		return v < below, err
+	}
+	ambiguous := func(s string) bool {
+		return config.NoAmbiguous && strings.ContainsAny(s, ambiguousChars)
+	}
+	for {
+		buf := make([]rune, 0, length)
+		needUppers, needDigits, needSymbols := uppers, digits, symbols
+		var shouldBe, prev int
+		first := true
+		nextStart := func() error {
+			vowel, err := chance(2, 1)
+			shouldBe = phonemeConsonant
+			if vowel {
+				shouldBe = phonemeVowel
+			}
+			return err
+		}
+		if err := nextStart(); err != nil {
+			return nil, err
+		}
+		retry := false
+		for len(buf) < length && !retry {
+			index, err := randomInt(r, len(phonemes))
+			if err != nil {
+				return nil, err
+			}
+			element := phonemes[index]
+			if element.flags&shouldBe == 0 ||
+				(first && element.flags&phonemeNotFirst != 0) ||
+				(prev&phonemeVowel != 0 && element.flags&phonemeVowel != 0 && element.flags&phonemeDipthong != 0) ||
+				len(element.chars) > length-len(buf) {
+				continue
+			}
+			chars := []rune(element.chars)
+			if uppers && (first || element.flags&phonemeConsonant != 0) {
+				upper, err := chance(10, 2)
+				if err != nil {
+					return nil, err
+				}
+				if upper {
+					chars[0] = unicode.ToUpper(chars[0])
+					needUppers = false
+				}
+			}
+			if ambiguous(string(chars)) {
+				retry = true
+				continue
+			}
+			buf = append(buf, chars...)
+			if len(buf) >= length {
+				break
+			}
+
+			if digits && !first {
+				digit, err := chance(10, 3)
+				if err != nil {
+					return nil, err
+				}
+				if digit {
+					// ambiguous digits are redrawn
+					var d int
+					for d = -1; d < 0 || ambiguous(string(rune('0'+d))); {
+						if d, err = randomInt(r, 10); err != nil {
+							return nil, err
+						}
+					}
+					buf = append(buf, rune('0'+d))
+					needDigits = false
+					first = true
+					prev = 0
+					if err := nextStart(); err != nil {
+						return nil, err
+					}
+					continue
+				}
+			}
+			if symbols && !first {
+				symbol, err := chance(10, 2)
+				if err != nil {
+					return nil, err
+				}
+				if symbol {
+					var ch rune
+					for ch = 0; ch == 0 || ambiguous(string(ch)); {
+						index, err := randomInt(r, len(pronounceableSymbols))
+						if err != nil {
+							return nil, err
+						}
+						ch = rune(pronounceableSymbols[index])
+					}
+					buf = append(buf, ch)
+					needSymbols = false
+				}
+			}
+
+			if shouldBe == phonemeConsonant {
+				shouldBe = phonemeVowel
+			} else {
+				consonant, err := chance(10, 6)
+				if err != nil {
+					return nil, err
+				}
+				if prev&phonemeVowel != 0 || element.flags&phonemeDipthong != 0 || consonant {
+					shouldBe = phonemeConsonant
+				} else {
+					shouldBe = phonemeVowel
+				}
+			}
+			prev = element.flags
+			first = false
+		}
+		if !retry && !needUppers && !needDigits && !needSymbols {
+			return buf[:length], nil
+		}
+	}
+}
+
+// pronounceableEntropy estimates the entropy of pronounceable passwords by the choices of phonemes,
+// ignoring mixed upper cases, digits and symbols. Different choices may spell the same password,
+// so it's an approximation rather than a bound.
+func pronounceableEntropy(length int) float64 {
+	type state struct {
+		rest, shouldBe, prev int
+		first                bool
+	}
+	memo := make(map[state]float64)
+	var entropy func(s state) float64
+	entropy = func(s state) float64 {
+		if s.rest <= 0 {
+			return 0
+		}
+		if v, ok := memo[s]; ok {
+			return v
+		}
+		valid := make([]phoneme, 0, len(phonemes))
+		for _, element := range phonemes {
+			if element.flags&s.shouldBe == 0 ||
+				(s.first && element.flags&phonemeNotFirst != 0) ||
+				(s.prev&phonemeVowel != 0 && element.flags&phonemeVowel != 0 && element.flags&phonemeDipthong != 0) ||
+				len(element.chars) > s.rest {
+				continue
+			}
+			valid = append(valid, element)
+		}
+		if len(valid) == 0 {
+			return 0
+		}
+		bits := math.Log2(float64(len(valid)))
+		for _, element := range valid {
+			next := state{rest: s.rest - len(element.chars), prev: element.flags}
+			if s.shouldBe == phonemeConsonant {
+				next.shouldBe = phonemeVowel
+				bits += entropy(next) / float64(len(valid))
+			} else if s.prev&phonemeVowel != 0 || element.flags&phonemeDipthong != 0 {
+				next.shouldBe = phonemeConsonant
+				bits += entropy(next) / float64(len(valid))
+			} else {
+				// consonant by 6/10, vowel by 4/10
+				consonant, vowel := next, next
+				consonant.shouldBe, vowel.shouldBe = phonemeConsonant, phonemeVowel
+				binary := -(0.6*math.Log2(0.6) + 0.4*math.Log2(0.4))
+				bits += (binary + 0.6*entropy(consonant) + 0.4*entropy(vowel)) / float64(len(valid))
+			}
+		}
+		memo[s] = bits
+		return bits
+	}
+	// the first phoneme is a consonant or a vowel by 1/2
+	return 1 + (entropy(state{rest: length, shouldBe: phonemeConsonant, first: true})+entropy(state{rest: length, shouldBe: phonemeVowel, first: true}))/2
+}
+
+// Hyphenate splits a pronounceable password into syllables joined by '-', like the hyphenated output of APG.
+// A syllable ends before a consonant following its vowels, 'h' of "ah" and "oh" is kept with the vowel,
+// consonants without vowels are kept with the previous syllable, and each run of digits and symbols is a syllable by itself.
+func Hyphenate(s string) string {
+	isVowel := func(r rune) bool {
+		return strings.ContainsRune("aeiou", unicode.ToLower(r))
+	}
+	syllables := make([]string, 0)
+	var current []rune
+	nucleus := false
+	flush := func() {
+		if n := len(syllables); n > 0 && len(current) > 0 && !nucleus && unicode.IsLetter(current[0]) &&
+			strings.IndexFunc(syllables[n-1], isVowel) >= 0 {
+			syllables[n-1] += string(current)
+			current = nil
+		}
+		if len(current) > 0 {
+			syllables = append(syllables, string(current))
+			current = nil
+			nucleus = false
+		}
+	}
+	for _, r := range s {
+		if len(current) > 0 {
+			last := current[len(current)-1]
+			switch {
+			case unicode.IsLetter(r) != unicode.IsLetter(last):
+				flush()
+			case unicode.IsLetter(r) && nucleus && !isVowel(r) && !(unicode.ToLower(r) == 'h' && isVowel(last)):
+				flush()
+			}
+		}
+		current = append(current, r)
+		nucleus = nucleus || isVowel(r)
+	}
+	flush()
+	return strings.Join(syllables, "-")
+}
@@ -0,0 +1,34 @@
+package gotpasswd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHyphenate(t *testing.T) {
+	for s, want := range map[string]string{
+		"Ohtaeshi7":   "Oh-tae-shi-7",
+		"ahxiecho42!": "ah-xie-cho-42!",
+		"ieChei9pu":   "ie-Chei-9-pu",
+		"brst":        "brst",
+	} {
+		if got := Hyphenate(s); got != want {
+			t.Errorf("Hyphenate(%q) = %q, want %q", s, got, want)
+		}
+	}
+
+	config := &Config{Kinds: []CharacterKind{ALPHABET, NUMBER, SYMBOL}, Length: 16, Pronounceable: true}
+	for i := 0; i < 50; i++ {
+		passwd, err := Generate(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(passwd, "-") {
+			continue
+		}
+		hyphenated := Hyphenate(passwd)
+		if strings.ReplaceAll(hyphenated, "-", "") != passwd {
+			t.Fatalf("syllables %q are not of %q", hyphenated, passwd)
+		}
+	}
+}
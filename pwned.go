@@ -0,0 +1,129 @@
+package gotpasswd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Range API of Pwned Passwords, queried by the first 5 characters of SHA-1
+const pwnedRangeURL = "https://api.pwnedpasswords.com/range/"
+
+// PwnedValidator rejects passwords found in breaches of Have I Been Pwned, by the k-anonymity range API,
+// or by a local dump of "SHA1:COUNT" lines ordered by hash such as downloaded from the service.
+// Only the first 5 characters of SHA-1 of passwords are sent to the API.
+type PwnedValidator struct {
+	// Dump file searched instead of the API, if not empty
+	DumpPath string
+	// Client of the API, http.DefaultClient if nil
+	Client *http.Client
+}
+
+func (self *PwnedValidator) Validate(ctx context.Context, passwd string) (bool, error) {
+	count, err := self.Count(ctx, passwd)
+	return count == 0, err
+}
+
+// Count returns how many times the password appeared in breaches.
+func (self *PwnedValidator) Count(ctx context.Context, passwd string) (int, error) {
+	sum := sha1.Sum([]byte(passwd))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	if self.DumpPath != "" {
+		return searchPwnedDump(self.DumpPath, hash)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, pwnedRangeURL+hash[:5], nil)
+	if err != nil {
+		return 0, err
+	}
+	// padded responses hide the number of matching suffixes from observers
+	request.Header.Set("Add-Padding", "true")
+	client := self.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return 0, errors.New(fmt.Sprintf("Pwned Passwords API responded %s", response.Status))
+	}
+	return findPwnedCount(response.Body, hash[5:])
+}
+
+// findPwnedCount finds "SUFFIX:COUNT" of the suffix in lines of r.
+func findPwnedCount(r io.Reader, suffix string) (int, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		pair := strings.SplitN(strings.TrimSpace(scanner.Text()), ":", 2)
+		if len(pair) == 2 && strings.EqualFold(pair[0], suffix) {
+			return strconv.Atoi(pair[1])
+		}
+	}
+	return 0, scanner.Err()
+}
+
+// searchPwnedDump finds the hash in the dump by binary search of lines, since dumps are too large to scan.
+func searchPwnedDump(path string, hash string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	// lineAt returns the first line starting after offset, which is the first line of the file at 0
+	buf := make([]byte, 256)
+	lineAt := func(offset int64) (string, error) {
+		n, err := file.ReadAt(buf, offset)
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		chunk := buf[:n]
+		if offset > 0 {
+			i := bytes.IndexByte(chunk, '\n')
+			if i < 0 {
+				return "", nil
+			}
+			chunk = chunk[i+1:]
+		}
+		if i := bytes.IndexByte(chunk, '\n'); i >= 0 {
+			chunk = chunk[:i]
+		}
+		return strings.TrimSpace(string(chunk)), nil
+	}
+
+	// lines found at offsets are ordered, find the first one not before the hash
+	lo, hi := int64(0), info.Size()
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		line, err := lineAt(mid)
+		if err != nil {
+			return 0, err
+		}
+		if line != "" && strings.ToUpper(line[:min(len(line), len(hash))]) < hash {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	line, err := lineAt(lo)
+	if err != nil {
+		return 0, err
+	}
+	return findPwnedCount(strings.NewReader(line), hash)
+}
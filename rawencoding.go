@@ -0,0 +1,51 @@
+package gotpasswd
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// Alphabet of base58 of Bitcoin, without 0, O, I and l
+const base58 = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// Encodings of random bytes for Config.RawEncoding
+var rawEncodings = map[string]func([]byte) string{
+	"hex":       hex.EncodeToString,
+	"base64":    base64.StdEncoding.EncodeToString,
+	"base64url": base64.RawURLEncoding.EncodeToString,
+	"base58":    encodeBase58,
+}
+
+func encodeBase58(b []byte) string {
+	n := new(big.Int).SetBytes(b)
+	radix := big.NewInt(int64(len(base58)))
+	mod := new(big.Int)
+	chars := make([]byte, 0, len(b)*138/100+1)
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		chars = append(chars, base58[mod.Int64()])
+	}
+	// leading zero bytes are kept as '1'
+	for _, v := range b {
+		if v != 0 {
+			break
+		}
+		chars = append(chars, base58[0])
+	}
+	for i, j := 0, len(chars)-1; i < j; i, j = i+1, j-1 {
+		chars[i], chars[j] = chars[j], chars[i]
+	}
+	return string(chars)
+}
+
+func generateRawEncoded(r io.Reader, encoding string, length int) ([]rune, error) {
+	b := make([]byte, length)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, errors.New(fmt.Sprintf("Cannot read randomness: %s", err))
+	}
+	return []rune(rawEncodings[encoding](b)), nil
+}
@@ -0,0 +1,88 @@
+package gotpasswd
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Lowercase letters and digits without ambiguous ones, default alphabet of recovery codes
+const recoveryAlphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+
+// RecoveryCodes is a set of recovery codes, such as of 2FA backups, in the style of "xxxxx-xxxxx".
+type RecoveryCodes struct {
+	// Number of codes in the set
+	Count int
+	// Number of characters of each group, and number of groups of each code
+	GroupSize int
+	Groups    int
+	// Characters of codes, recoveryAlphabet if empty
+	Alphabet []rune
+	// Separator of groups, "-" if nil
+	Separator *string
+}
+
+func (self *RecoveryCodes) alphabet() []rune {
+	if len(self.Alphabet) == 0 {
+		return []rune(recoveryAlphabet)
+	}
+	return uniqueRunes(self.Alphabet)
+}
+
+// Validate reports a problem of the set, such as more codes than the distinct ones.
+func (self *RecoveryCodes) Validate() error {
+	if self.Count <= 0 || self.GroupSize <= 0 || self.Groups <= 0 {
+		return errors.New("Number of recovery codes, size of groups and number of groups must be positive")
+	}
+	// codes are drawn without duplicates, too many of them would take long
+	if bits := float64(self.GroupSize*self.Groups) * math.Log2(float64(len(self.alphabet()))); bits < math.Log2(float64(self.Count))+1 {
+		return errors.New(fmt.Sprintf("Recovery codes are too short to make %d distinct codes", self.Count))
+	}
+	return nil
+}
+
+// Entropy returns the entropy in bits of each code.
+func (self *RecoveryCodes) Entropy() float64 {
+	return float64(self.GroupSize*self.Groups) * math.Log2(float64(len(self.alphabet())))
+}
+
+// GenerateRecoveryCodes returns distinct codes of the set.
+func (self *Generator) GenerateRecoveryCodes(set *RecoveryCodes) ([]string, error) {
+	if err := set.Validate(); err != nil {
+		return nil, err
+	}
+	r := self.rand
+	if r == nil {
+		r = rand.Reader
+	}
+	separator := "-"
+	if set.Separator != nil {
+		separator = *set.Separator
+	}
+	alphabet := set.alphabet()
+	codes := make([]string, 0, set.Count)
+	seen := make(map[string]bool)
+	// Validate leaves twice the codes to draw from, duplicates in a row are of a broken source
+	retries := 0
+	for len(codes) < set.Count {
+		groups := make([]string, set.Groups)
+		for i := range groups {
+			chars, err := randomRunes(r, alphabet, set.GroupSize)
+			if err != nil {
+				return nil, err
+			}
+			groups[i] = string(chars)
+		}
+		code := strings.Join(groups, separator)
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+			retries = 0
+		} else if retries++; retries >= maxRetries {
+			return nil, errors.New(fmt.Sprintf("Cannot draw %d distinct recovery codes in %d retries", set.Count, maxRetries))
+		}
+	}
+	return codes, nil
+}
@@ -0,0 +1,44 @@
+package gotpasswd
+
+import (
+	"regexp"
+	"testing"
+)
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	set := &RecoveryCodes{Count: 10, GroupSize: 5, Groups: 2}
+	codes, err := NewGenerator(nil).GenerateRecoveryCodes(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pattern := regexp.MustCompile(`^[` + recoveryAlphabet + `]{5}-[` + recoveryAlphabet + `]{5}$`)
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		if !pattern.MatchString(code) || seen[code] {
+			t.Errorf("%q is not a distinct code", code)
+		}
+		seen[code] = true
+	}
+	if len(codes) != 10 {
+		t.Errorf("got %d codes, want 10", len(codes))
+	}
+}
+
+func TestGenerateRecoveryCodesRetries(t *testing.T) {
+	// the source repeats the same code forever
+	set := &RecoveryCodes{Count: 2, GroupSize: 5, Groups: 2}
+	if _, err := NewGenerator(zeroReader{}).GenerateRecoveryCodes(set); err == nil {
+		t.Error("GenerateRecoveryCodes must give up on duplicates")
+	}
+	set = &RecoveryCodes{Count: 100, GroupSize: 1, Groups: 1}
+	if err := set.Validate(); err == nil {
+		t.Error("32 letters must not make 100 distinct codes")
+	}
+}
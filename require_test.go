@@ -0,0 +1,51 @@
+package gotpasswd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRequireOneOf(t *testing.T) {
+	groups := [][]rune{[]rune("$+"), []rune("^~"), []rune("0123")}
+	config := &Config{Kinds: []CharacterKind{ALPHABET, NUMBER, SYMBOL}, Length: 16, RequireOneOf: groups}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		passwd, err := Generate(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, group := range groups {
+			if !strings.ContainsAny(passwd, string(group)) {
+				t.Fatalf("%q has none of %q", passwd, string(group))
+			}
+		}
+	}
+}
+
+func TestRequireOneOfErrors(t *testing.T) {
+	for _, groups := range [][]string{
+		{""},
+		// not in the candidates
+		{"€"},
+		// 3 characters of their own in 2 characters
+		{"$", "+", "^"},
+	} {
+		config := &Config{Kinds: []CharacterKind{ALPHABET, NUMBER, SYMBOL}, Length: 2}
+		for _, group := range groups {
+			config.RequireOneOf = append(config.RequireOneOf, []rune(group))
+		}
+		if err := config.Validate(); err == nil {
+			t.Errorf("%q must fail", groups)
+		}
+	}
+	// groups sharing a character are satisfied by it
+	config := &Config{Kinds: []CharacterKind{SYMBOL}, Length: 1, RequireOneOf: [][]rune{[]rune("$+"), []rune("$<"), []rune("=$")}}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if passwd, err := Generate(config); err != nil || passwd != "$" {
+		t.Errorf("Generate = %q, %v, want $", passwd, err)
+	}
+}
@@ -0,0 +1,31 @@
+package gotpasswd
+
+// Characters needing quotes or escapes in contexts where passwords are pasted.
+var unsafeChars = map[string]string{
+	// quotes, expansions, globs, redirections and separators of POSIX shells and zsh
+	"shell": " \t\"#$&'()*;<>?[\\]^`{|}~!",
+	// indicators of YAML plain scalars, and quotes
+	"yaml": " \t\"#&'*,:>?@[\\]`{|}!%",
+	// quotes, comments, expansions and assignments of .env files loaded by shells and dotenv
+	"env": " \t\"#$'\\`=",
+	// reserved and unsafe characters of URLs, other than unreserved ones; non-ASCII characters are also dropped
+	"url": " !\"#$%&'()*+,/:;<=>?@[\\]^`{|}",
+}
+
+// UnsafeContexts returns names of contexts for SafeFor.
+func UnsafeContexts() []string {
+	return sortedKeys(unsafeChars)
+}
+
+// safeFor reports whether r can be used as is in the context.
+func safeFor(context string, r rune) bool {
+	if context == "url" && r >= 0x80 {
+		return false
+	}
+	for _, c := range unsafeChars[context] {
+		if c == r {
+			return false
+		}
+	}
+	return true
+}
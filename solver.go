@@ -0,0 +1,230 @@
+package gotpasswd
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"maps"
+	"math/big"
+	"math/bits"
+	"slices"
+)
+
+// solve constructs a password satisfying the structural constraints (MinKinds, RequireAllKinds, MinCounts,
+// MinTransitions, Positions, StartWith and EndWith) instead of rejection sampling. Kinds of each position are sampled by
+// dynamic programming over the number of valid completions, so every valid password is chosen with the equal probability.
+// Other constraints are left to rejection sampling.
+func solve(r io.Reader, config *Config, charCandidates []rune, length int) ([]rune, error) {
+	// kinds of MinCounts are counted up to their minimums
+	countKinds := make([]CharacterKind, 0)
+	for kind, min := range config.MinCounts {
+		if min > 0 {
+			countKinds = append(countKinds, kind)
+		}
+	}
+	slices.Sort(countKinds)
+
+	// group characters of each position by their kind and kinds of MinCounts containing them,
+	// -1 is the kind of ones out of the dictionary
+	type class struct {
+		kind    CharacterKind
+		counted int
+	}
+	classIndex := make(map[class]int)
+	classes := make([]class, 0)
+	classOf := func(ch rune) int {
+		key := class{kind: -1}
+		if kind, ok := KindOf(ch); ok {
+			key.kind = kind
+		}
+		for k, kind := range countKinds {
+			if kindContains(kind, ch) {
+				key.counted |= 1 << k
+			}
+		}
+		if index, ok := classIndex[key]; ok {
+			return index
+		}
+		classIndex[key] = len(classes)
+		classes = append(classes, key)
+		return classIndex[key]
+	}
+	groupByClass := func(chars []rune) map[int][]rune {
+		groups := make(map[int][]rune)
+		for _, ch := range chars {
+			c := classOf(ch)
+			groups[c] = append(groups[c], ch)
+		}
+		return groups
+	}
+	// alphabets of the first and last characters are ones of Positions narrowed by StartWith and EndWith
+	alphabets := make(map[int][]rune)
+	maps.Copy(alphabets, config.Positions)
+	maps.Copy(alphabets, config.edgeAlphabets(charCandidates, length))
+	candidates := groupByClass(charCandidates)
+	groups := make([]map[int][]rune, length)
+	for i := range groups {
+		if alphabet, ok := alphabets[i]; ok {
+			groups[i] = groupByClass(alphabet)
+		} else {
+			groups[i] = candidates
+		}
+	}
+	numClasses := len(classes)
+
+	// kinds used so far are a mask of kinds, kinds out of the dictionary don't count for MinKinds
+	kindBits := make(map[CharacterKind]int)
+	for _, class := range classes {
+		if _, ok := kindBits[class.kind]; !ok && class.kind >= 0 {
+			kindBits[class.kind] = len(kindBits)
+		}
+	}
+	usesMask := config.MinKinds > 0 || config.RequireAllKinds
+	kindMask := func(c int) int {
+		if !usesMask || classes[c].kind < 0 {
+			return 0
+		}
+		return 1 << kindBits[classes[c].kind]
+	}
+	numMasks := 1
+	if usesMask {
+		numMasks = 1 << len(kindBits)
+	}
+	// each of required kinds is satisfied by any of its kinds, both of upper and lower for alphabet
+	requiredMasks := make([]int, 0)
+	if config.RequireAllKinds {
+		for _, kind := range config.Kinds {
+			mask := 0
+			for classKind, bit := range kindBits {
+				if classKind == kind || (kind == ALPHABET && (classKind == UPPER || classKind == LOWER)) {
+					mask |= 1 << bit
+				}
+			}
+			requiredMasks = append(requiredMasks, mask)
+		}
+	}
+
+	// counts of kinds of MinCounts are digits of a mixed radix number, all of them are at their minimums at last
+	numCounts := 1
+	for _, kind := range countKinds {
+		numCounts *= config.MinCounts[kind] + 1
+	}
+	nextCounts := make([][]int, numCounts)
+	for s := range nextCounts {
+		nextCounts[s] = make([]int, numClasses)
+		for c := range nextCounts[s] {
+			next, unit, rest := 0, 1, s
+			for k, kind := range countKinds {
+				radix := config.MinCounts[kind] + 1
+				digit := rest % radix
+				if classes[c].counted&(1<<k) != 0 {
+					digit = min(digit+1, radix-1)
+				}
+				next += digit * unit
+				unit *= radix
+				rest /= radix
+			}
+			nextCounts[s][c] = next
+		}
+	}
+
+	// a state is a pair of the kinds used and the counts of kinds
+	numStates := numMasks * numCounts
+	nextState := func(state, c int) int {
+		m, s := state/numCounts, state%numCounts
+		return (m|kindMask(c))*numCounts + nextCounts[s][c]
+	}
+	accepts := func(state int) bool {
+		m, s := state/numCounts, state%numCounts
+		if s != numCounts-1 {
+			return false
+		}
+		for _, mask := range requiredMasks {
+			if m&mask == 0 {
+				return false
+			}
+		}
+		return bits.OnesCount(uint(m)) >= config.MinKinds
+	}
+	maxTransitions := config.MinTransitions
+	nextTransitions := func(t int, prev, next int) int {
+		if prev != next && t < maxTransitions {
+			return t + 1
+		}
+		return t
+	}
+	weight := func(i, c int) *big.Int {
+		return big.NewInt(int64(len(groups[i][c])))
+	}
+
+	// completions[i][c][t][state]: number of ways to fill positions after i,
+	// when position i is class c, with t transitions and the state so far
+	completions := make([][][][]*big.Int, length)
+	for i := length - 1; i >= 0; i-- {
+		completions[i] = make([][][]*big.Int, numClasses)
+		for c := 0; c < numClasses; c++ {
+			completions[i][c] = make([][]*big.Int, maxTransitions+1)
+			for t := 0; t <= maxTransitions; t++ {
+				completions[i][c][t] = make([]*big.Int, numStates)
+				for state := 0; state < numStates; state++ {
+					n := new(big.Int)
+					if i == length-1 {
+						if t >= config.MinTransitions && accepts(state) {
+							n.SetInt64(1)
+						}
+					} else {
+						for next := 0; next < numClasses; next++ {
+							w := weight(i+1, next)
+							if w.Sign() == 0 {
+								continue
+							}
+							rest := completions[i+1][next][nextTransitions(t, c, next)][nextState(state, next)]
+							n.Add(n, new(big.Int).Mul(w, rest))
+						}
+					}
+					completions[i][c][t][state] = n
+				}
+			}
+		}
+	}
+
+	chars := make([]rune, length)
+	prev, t, state := -1, 0, 0
+	for i := 0; i < length; i++ {
+		ways := make([]*big.Int, numClasses)
+		total := new(big.Int)
+		for c := 0; c < numClasses; c++ {
+			nt := t
+			if prev >= 0 {
+				nt = nextTransitions(t, prev, c)
+			}
+			ways[c] = new(big.Int).Mul(weight(i, c), completions[i][c][nt][nextState(state, c)])
+			total.Add(total, ways[c])
+		}
+		if total.Sign() == 0 {
+			return nil, errors.New("Constraints cannot be satisfied with the length and kinds")
+		}
+		v, err := rand.Int(r, total)
+		if err != nil {
+			return nil, err
+		}
+		c := 0
+		for ; c < numClasses; c++ {
+			if v.Cmp(ways[c]) < 0 {
+				break
+			}
+			v.Sub(v, ways[c])
+		}
+		index, err := randomInt(r, len(groups[i][c]))
+		if err != nil {
+			return nil, err
+		}
+		chars[i] = groups[i][c][index]
+		if prev >= 0 {
+			t = nextTransitions(t, prev, c)
+		}
+		state = nextState(state, c)
+		prev = c
+	}
+	return chars, nil
+}
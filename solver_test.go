@@ -0,0 +1,78 @@
+package gotpasswd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSolver(t *testing.T) {
+	// rejection sampling satisfies them once in about 10^11 passwords
+	config := &Config{
+		Kinds:     []CharacterKind{ALPHABET, NUMBER, SYMBOL},
+		Length:    14,
+		MinCounts: map[CharacterKind]int{NUMBER: 9, SYMBOL: 3},
+		Positions: map[int][]rune{1: []rune("Ab")},
+		StartWith: []CharacterKind{NUMBER},
+		EndWith:   []CharacterKind{SYMBOL},
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Generate(config); err == nil {
+		t.Error("rejection sampling must exhaust retries")
+	}
+
+	config.Solver = true
+	for i := 0; i < 100; i++ {
+		passwd, err := Generate(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chars := []rune(passwd)
+		if reason := violation(config, chars); reason != "" {
+			t.Fatalf("%q is rejected for %s", passwd, reason)
+		}
+		if !strings.ContainsRune("Ab", chars[1]) {
+			t.Fatalf("%q is not of the alphabet at position 1", passwd)
+		}
+	}
+}
+
+func TestSolverUnsatisfiable(t *testing.T) {
+	config := &Config{
+		Kinds:     []CharacterKind{ALPHABET, NUMBER},
+		Length:    4,
+		MinCounts: map[CharacterKind]int{NUMBER: 3},
+		StartWith: []CharacterKind{ALPHABET},
+		EndWith:   []CharacterKind{ALPHABET},
+		Solver:    true,
+	}
+	if _, err := Generate(config); err == nil {
+		t.Error("3 numbers cannot be in 2 characters between alphabets")
+	}
+}
+
+func TestValidatePositionsAndEdges(t *testing.T) {
+	config := &Config{
+		Kinds:     []CharacterKind{ALPHABET, NUMBER},
+		Length:    8,
+		Positions: map[int][]rune{0: []rune("A")},
+		StartWith: []CharacterKind{NUMBER},
+	}
+	if err := config.Validate(); err == nil {
+		t.Error("position 0 of no numbers must conflict with the first character of numbers")
+	}
+	config.Positions = map[int][]rune{0: []rune("A1")}
+	if err := config.Validate(); err != nil {
+		t.Error(err)
+	}
+	for i := 0; i < 20; i++ {
+		passwd, err := Generate(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if passwd[0] != '1' {
+			t.Fatalf("%q must start with 1", passwd)
+		}
+	}
+}
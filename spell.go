@@ -0,0 +1,47 @@
+package gotpasswd
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Words of the NATO phonetic alphabet
+var natoWords = []string{
+	"Alfa", "Bravo", "Charlie", "Delta", "Echo", "Foxtrot", "Golf", "Hotel", "India", "Juliett", "Kilo", "Lima", "Mike",
+	"November", "Oscar", "Papa", "Quebec", "Romeo", "Sierra", "Tango", "Uniform", "Victor", "Whiskey", "X-ray", "Yankee", "Zulu",
+}
+
+// Digits are upper cased, not to be confused with words of letters
+var digitWords = []string{"ZERO", "ONE", "TWO", "THREE", "FOUR", "FIVE", "SIX", "SEVEN", "EIGHT", "NINE"}
+
+var symbolWords = map[rune]string{
+	' ': "space", '!': "exclamation", '"': "double quote", '#': "hash", '$': "dollar", '%': "percent", '&': "ampersand",
+	'\'': "single quote", '(': "left paren", ')': "right paren", '*': "asterisk", '+': "plus", ',': "comma",
+	'-': "hyphen", '.': "period", '/': "slash", ':': "colon", ';': "semicolon", '<': "less than", '=': "equals",
+	'>': "greater than", '?': "question", '@': "at", '[': "left bracket", '\\': "backslash", ']': "right bracket",
+	'^': "caret", '_': "underscore", '`': "backquote", '{': "left brace", '|': "pipe", '}': "right brace", '~': "tilde",
+}
+
+// Spell spells out each character for reading passwords aloud, such as "Alfa lower, SEVEN, Victor UPPER, dollar".
+// Other characters are spelled by themselves and their code points.
+func Spell(s string) string {
+	words := make([]string, 0)
+	for _, r := range s {
+		switch {
+		case 'a' <= r && r <= 'z':
+			words = append(words, natoWords[r-'a']+" lower")
+		case 'A' <= r && r <= 'Z':
+			words = append(words, natoWords[r-'A']+" UPPER")
+		case '0' <= r && r <= '9':
+			words = append(words, digitWords[r-'0'])
+		case symbolWords[r] != "":
+			words = append(words, symbolWords[r])
+		case unicode.IsPrint(r):
+			words = append(words, fmt.Sprintf("%c (U+%04X)", r, r))
+		default:
+			words = append(words, fmt.Sprintf("U+%04X", r))
+		}
+	}
+	return strings.Join(words, ", ")
+}
@@ -0,0 +1,39 @@
+package gotpasswd
+
+import (
+	"strings"
+)
+
+// Characters needing escapes in string literals of SQL dialects.
+// Standard SQL only doubles single quotes, MySQL also treats backslashes as escapes by default.
+var sqlDialects = map[string]string{
+	"ansi":     "'",
+	"postgres": "'",
+	"sqlite":   "'",
+	"mssql":    "'",
+	"mysql":    "'\\",
+}
+
+// SQLDialects returns names of dialects for SQLSafe and SQLLiteral.
+func SQLDialects() []string {
+	return sortedKeys(sqlDialects)
+}
+
+// SQLLiteral returns s as a string literal of the dialect.
+// Literals of mysql escape backslashes, they are wrong on servers in NO_BACKSLASH_ESCAPES mode, use ansi for them.
+func SQLLiteral(s string, dialect string) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	for _, r := range s {
+		if strings.ContainsRune(sqlDialects[dialect], r) {
+			if r == '\'' {
+				b.WriteByte('\'')
+			} else {
+				b.WriteByte('\\')
+			}
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
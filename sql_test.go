@@ -0,0 +1,64 @@
+package gotpasswd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSQLLiteral(t *testing.T) {
+	for _, dialect := range SQLDialects() {
+		want := `'it''s a\b'`
+		if dialect == "mysql" {
+			want = `'it''s a\\b'`
+		}
+		if got := SQLLiteral(`it's a\b`, dialect); got != want {
+			t.Errorf("SQLLiteral(%s) = %s, want %s", dialect, got, want)
+		}
+		for _, s := range []string{"", "'", `\`, `\'`, `'\''`, "a'b\\c"} {
+			if got, ok := unquoteSQL(SQLLiteral(s, dialect), dialect); !ok || got != s {
+				t.Errorf("%s: literal of %q is parsed as %q, %v", dialect, s, got, ok)
+			}
+		}
+	}
+}
+
+// unquoteSQL parses a string literal as the dialect does, ok is false if it does not end at the last quote.
+func unquoteSQL(literal string, dialect string) (string, bool) {
+	if !strings.HasPrefix(literal, "'") {
+		return "", false
+	}
+	var b strings.Builder
+	rest := literal[1:]
+	for rest != "" {
+		switch {
+		case dialect == "mysql" && rest[0] == '\\' && len(rest) > 1:
+			b.WriteByte(rest[1])
+			rest = rest[2:]
+		case strings.HasPrefix(rest, "''"):
+			b.WriteByte('\'')
+			rest = rest[2:]
+		case rest[0] == '\'':
+			return b.String(), len(rest) == 1
+		default:
+			b.WriteByte(rest[0])
+			rest = rest[1:]
+		}
+	}
+	return b.String(), false
+}
+
+func TestSQLSafe(t *testing.T) {
+	for _, dialect := range SQLDialects() {
+		config := &Config{Kinds: []CharacterKind{ALPHABET, NUMBER, SYMBOL}, Length: 32, ExtraChars: []rune(`'\`), SQLSafe: dialect}
+		if err := config.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		passwd, err := Generate(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.ContainsAny(passwd, sqlDialects[dialect]) {
+			t.Errorf("%s: %q needs escapes", dialect, passwd)
+		}
+	}
+}
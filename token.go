@@ -0,0 +1,63 @@
+package gotpasswd
+
+import (
+	"hash/crc32"
+	"io"
+	"math"
+	"strings"
+)
+
+const base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// Length of checksums of tokens, CRC32 fits in 6 base62 digits
+const tokenChecksumLength = 6
+
+// TokenFormat is a format of API tokens in the style of GitHub, such as "ghp_" and a base62 body.
+type TokenFormat struct {
+	Prefix string
+	// Append the base62 CRC32 of the body, to detect leaked tokens without a lookup
+	Checksum bool
+}
+
+// tokenChecksum encodes CRC32 of the body in base62, padded by zeros.
+func tokenChecksum(body string) string {
+	sum := crc32.ChecksumIEEE([]byte(body))
+	chars := make([]byte, tokenChecksumLength)
+	for i := len(chars) - 1; i >= 0; i-- {
+		chars[i] = base62[sum%62]
+		sum /= 62
+	}
+	return string(chars)
+}
+
+func generateToken(r io.Reader, format *TokenFormat, length int) ([]rune, error) {
+	body, err := randomRunes(r, []rune(base62), length)
+	if err != nil {
+		return nil, err
+	}
+	token := format.Prefix + string(body)
+	if format.Checksum {
+		token += tokenChecksum(string(body))
+	}
+	return []rune(token), nil
+}
+
+func tokenEntropy(length int) float64 {
+	return float64(length) * math.Log2(float64(len(base62)))
+}
+
+// VerifyToken reports whether the token has the prefix and a valid checksum of its body.
+func VerifyToken(token string, prefix string) bool {
+	if !strings.HasPrefix(token, prefix) {
+		return false
+	}
+	rest := token[len(prefix):]
+	if len(rest) <= tokenChecksumLength {
+		return false
+	}
+	body, checksum := rest[:len(rest)-tokenChecksumLength], rest[len(rest)-tokenChecksumLength:]
+	if strings.Trim(body, base62) != "" {
+		return false
+	}
+	return tokenChecksum(body) == checksum
+}
@@ -0,0 +1,100 @@
+package gotpasswd
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Kinds of unicode categories (Lu, Nd, ...) and scripts (greek, cyrillic, ...), allocated after the ASCII kinds.
+// KindOf never returns them, as they overlap each other.
+var unicodeKinds = make(map[CharacterKind][]*unicode.RangeTable)
+
+var (
+	unicodeCharsMutex sync.Mutex
+	unicodeChars      = make(map[CharacterKind][]rune)
+)
+
+var (
+	// ぁ to ゖ
+	modernHiragana = &unicode.RangeTable{R16: []unicode.Range16{{Lo: 0x3041, Hi: 0x3096, Stride: 1}}}
+	// ァ to ヺ, and the prolonged sound mark ー
+	modernKatakana = &unicode.RangeTable{R16: []unicode.Range16{{Lo: 0x30a1, Hi: 0x30fa, Stride: 1}, {Lo: 0x30fc, Hi: 0x30fc, Stride: 1}}}
+)
+
+func init() {
+	tables := make(map[string][]*unicode.RangeTable)
+	for name, table := range unicode.Categories {
+		tables[name] = []*unicode.RangeTable{table}
+	}
+	for name, table := range unicode.Scripts {
+		tables[strings.ToLower(name)] = []*unicode.RangeTable{table}
+	}
+	// kana are the modern ones, instead of whole scripts with archaic and half-width ones
+	tables["hiragana"] = []*unicode.RangeTable{modernHiragana}
+	tables["katakana"] = []*unicode.RangeTable{modernKatakana}
+	tables["kana"] = []*unicode.RangeTable{modernHiragana, modernKatakana}
+
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		kind := firstUnicodeKind + CharacterKind(i)
+		kindNames[name] = kind
+		unicodeKinds[kind] = tables[name]
+	}
+}
+
+// unicodeKindChars returns graphic characters of the unicode kind, without combining marks unless the kind is of marks.
+func unicodeKindChars(kind CharacterKind) []rune {
+	unicodeCharsMutex.Lock()
+	defer unicodeCharsMutex.Unlock()
+	if chars, ok := unicodeChars[kind]; ok {
+		return chars
+	}
+	tables := unicodeKinds[kind]
+	marks := len(tables) == 1 && unicode.In(firstRune(tables[0]), unicode.M)
+	chars := make([]rune, 0)
+	for _, table := range tables {
+		for _, r16 := range table.R16 {
+			for r := rune(r16.Lo); r <= rune(r16.Hi); r += rune(r16.Stride) {
+				if unicode.IsGraphic(r) && (marks || !unicode.IsMark(r)) {
+					chars = append(chars, r)
+				}
+			}
+		}
+		for _, r32 := range table.R32 {
+			for r := rune(r32.Lo); r <= rune(r32.Hi); r += rune(r32.Stride) {
+				if unicode.IsGraphic(r) && (marks || !unicode.IsMark(r)) {
+					chars = append(chars, r)
+				}
+			}
+		}
+	}
+	unicodeChars[kind] = chars
+	return chars
+}
+
+func firstRune(table *unicode.RangeTable) rune {
+	if len(table.R16) > 0 {
+		return rune(table.R16[0].Lo)
+	}
+	return rune(table.R32[0].Lo)
+}
+
+// kindContains reports whether r is a character of the kind.
+func kindContains(kind CharacterKind, r rune) bool {
+	if tables, ok := unicodeKinds[kind]; ok {
+		return unicode.In(r, tables...)
+	}
+	k, ok := KindOf(r)
+	return ok && (k == kind || (kind == ALPHABET && (k == UPPER || k == LOWER)))
+}
+
+// Contains reports whether r is a character of the kind, ALPHABET contains both of UPPER and LOWER.
+func (self CharacterKind) Contains(r rune) bool {
+	return kindContains(self, r)
+}
@@ -0,0 +1,228 @@
+package gotpasswd
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Validate reports the first problem of config, such as unknown names and constraints which cannot be satisfied.
+func (self *Config) Validate() error {
+	if self.Length <= 0 {
+		return errors.New("Length of password must be positive")
+	}
+	if _, ok := layouts[self.Layout]; self.Layout != "" && !ok {
+		return errors.New(fmt.Sprintf("Unknown keyboard layout: %s", self.Layout))
+	}
+	if _, ok := sqlDialects[self.SQLSafe]; self.SQLSafe != "" && !ok {
+		return errors.New(fmt.Sprintf("Unknown SQL dialect: %s", self.SQLSafe))
+	}
+	if _, ok := fontConfusables[self.FontSafe]; self.FontSafe != "" && !ok {
+		return errors.New(fmt.Sprintf("Unknown font family: %s", self.FontSafe))
+	}
+	for _, context := range self.SafeFor {
+		if _, ok := unsafeChars[context]; !ok {
+			return errors.New(fmt.Sprintf("Unknown context of safe characters: %s", context))
+		}
+	}
+	if _, ok := targetEncodings[self.TargetEncoding]; self.TargetEncoding != "" && !ok {
+		return errors.New(fmt.Sprintf("Unknown encoding: %s", self.TargetEncoding))
+	}
+	if _, ok := identifiers[self.ID]; self.ID != "" && !ok {
+		return errors.New(fmt.Sprintf("Unknown identifier: %s", self.ID))
+	}
+	if _, ok := rawEncodings[self.RawEncoding]; self.RawEncoding != "" && !ok {
+		return errors.New(fmt.Sprintf("Unknown encoding of random bytes: %s", self.RawEncoding))
+	}
+	for index := range self.Positions {
+		if index >= self.Length {
+			return errors.New(fmt.Sprintf("Position %d is out of the length of password", index))
+		}
+	}
+	if self.Words < 0 || self.PinSuffix < 0 {
+		return errors.New("Number of words and PIN digits must not be negative")
+	}
+	if _, ok := wordlists[self.Wordlist]; self.Wordlist != "" && !ok {
+		return errors.New(fmt.Sprintf("Unknown wordlist: %s", self.Wordlist))
+	}
+	if self.Capitalize != "" && !capitalizations[self.Capitalize] {
+		return errors.New(fmt.Sprintf("Unknown capitalization: %s", self.Capitalize))
+	}
+	if self.InjectDigits < 0 || self.InjectSymbols < 0 {
+		return errors.New("Number of injected digits and symbols must not be negative")
+	}
+	if self.Mnemonic != 0 {
+		if !validMnemonicLength(self.Mnemonic) {
+			return errors.New("Number of words of BIP39 mnemonics must be one of 12, 15, 18, 21 and 24")
+		}
+		if err := validateMnemonicWordlist(self.mnemonicWordlist()); err != nil {
+			return err
+		}
+	}
+	if self.Words > 0 {
+		words := PassphraseWords(self)
+		if len(words) == 0 {
+			return errors.New("No words are left to generate passphrases")
+		}
+		if slices.Contains(words, "") {
+			return errors.New("Words of passphrases must not be empty")
+		}
+	}
+
+	candidates := Candidates(self)
+	if self.usesCandidates() && len(candidates) == 0 {
+		return errors.New("No characters are left to generate passwords")
+	}
+	for _, group := range self.RequireOneOf {
+		if len(group) == 0 {
+			return errors.New("Required characters must not be empty")
+		}
+		if self.usesCandidates() {
+			for _, r := range group {
+				if !strings.ContainsRune(string(candidates), r) {
+					return errors.New(fmt.Sprintf("Required character %q is not in the candidates", r))
+				}
+			}
+		}
+	}
+	if n := disjointGroups(self.RequireOneOf); self.usesCandidates() && n > self.Length {
+		return errors.New(fmt.Sprintf("%d groups of required characters share no characters, they cannot be in %d characters", n, self.Length))
+	}
+	if n := len(candidates); self.NoRepeatedBigrams && self.usesCandidates() && slices.Max(append([]int{self.Length}, self.AllowedLengths...))-1 > n*n {
+		return errors.New(fmt.Sprintf("Length of password is too long to avoid repeated bigrams, at most %d characters are available", n*n+1))
+	}
+	if self.usesCandidates() {
+		for index, alphabet := range self.edgeAlphabets(candidates, self.Length) {
+			if _, ok := self.Positions[index]; ok && len(alphabet) == 0 {
+				return errors.New(fmt.Sprintf("No characters of position %d are of the kinds of the first or last character", index))
+			} else if len(alphabet) == 0 {
+				return errors.New("No candidates are of the kinds of the first or last character")
+			}
+		}
+	}
+	if self.usesCandidates() && (self.NoRepeat || self.NoAdjacentRepeat) {
+		longest := self.Length
+		for _, length := range self.AllowedLengths {
+			longest = max(longest, length)
+		}
+		if self.NoRepeat && longest > len(candidates) {
+			return errors.New(fmt.Sprintf("Length of password is too long to avoid repeated characters, at most %d characters are available", len(candidates)))
+		}
+		if self.NoAdjacentRepeat && longest > 1 && len(candidates) < 2 {
+			return errors.New("At least 2 characters are required to avoid adjacent repeated characters")
+		}
+	}
+	if self.MinKinds > 0 && self.usesCandidates() && self.MinKinds > len(KindsOf(string(candidates))) {
+		return errors.New("Minimum number of kinds exceeds the number of kinds")
+	}
+	if self.RequireAllKinds {
+		if !self.usesCandidates() {
+			return errors.New("Requiring all kinds cannot be used with grammars, passphrases nor weighted charsets")
+		}
+		groups := make(map[CharacterKind]bool)
+		for _, kind := range self.Kinds {
+			if _, ok := unicodeKinds[kind]; ok {
+				return errors.New(fmt.Sprintf("Requiring all kinds cannot be used with the unicode kind %s", kind))
+			}
+			if !hasCandidates(kind, candidates) {
+				return errors.New(fmt.Sprintf("No %s characters are in the candidates", kind))
+			}
+			groups[kind] = true
+		}
+		// alphabet is implied by upper and lower
+		if groups[UPPER] || groups[LOWER] {
+			delete(groups, ALPHABET)
+		}
+		if len(groups) > self.Length {
+			return errors.New("Length of password is too short to contain all kinds")
+		}
+	}
+	required := 0
+	for kind, min := range self.MinCounts {
+		if min < 0 {
+			return errors.New(fmt.Sprintf("Minimum number of %s characters must not be negative", kind))
+		}
+		if limit, ok := self.MaxCounts[kind]; ok && limit < min {
+			return errors.New(fmt.Sprintf("Minimum number of %s characters exceeds the maximum", kind))
+		}
+		if min > 0 && self.usesCandidates() && !hasCandidates(kind, candidates) {
+			return errors.New(fmt.Sprintf("No %s characters are in the candidates", kind))
+		}
+		if kind == ALPHABET {
+			// letters required for upper and lower also count for alphabet
+			min -= self.MinCounts[UPPER] + self.MinCounts[LOWER]
+		}
+		required += max(min, 0)
+	}
+	if self.usesCandidates() && required > self.Length {
+		return errors.New("Minimum numbers of characters of kinds exceed the length of password")
+	}
+	for kind, max := range self.MaxCounts {
+		if max < 0 {
+			return errors.New(fmt.Sprintf("Maximum number of %s characters must not be negative", kind))
+		}
+	}
+	if self.MinTransitions < 0 {
+		return errors.New("Minimum number of transitions must not be negative")
+	}
+	if self.MinTransitions > 0 && self.usesCandidates() && (self.MinTransitions > self.Length-1 || len(KindsOf(string(candidates))) < 2) {
+		return errors.New("Minimum number of transitions cannot be satisfied with the length and kinds")
+	}
+	if self.MinCaseChanges < 0 {
+		return errors.New("Minimum number of case changes must not be negative")
+	}
+	if self.MinCaseChanges > 0 && self.usesCandidates() {
+		if self.MinCaseChanges > self.Length-1 || len(filterRunes(candidates, unicode.IsUpper)) == 0 || len(filterRunes(candidates, unicode.IsLower)) == 0 {
+			return errors.New("Minimum number of case changes cannot be satisfied with the length and kinds, both of upper and lower case letters are required")
+		}
+	}
+	if self.MinShannon < 0 {
+		return errors.New("Minimum Shannon entropy must not be negative")
+	}
+	// the entropy of n characters is at most log2(n), when all of them are distinct
+	if self.MinShannon > 0 && self.usesCandidates() && self.MinShannon > math.Log2(float64(min(self.Length, len(candidates)))) {
+		return errors.New("Minimum Shannon entropy cannot be satisfied with the length and kinds")
+	}
+	if self.KindWindow != nil && self.usesCandidates() && !self.KindWindow.Feasible(len(KindsOf(string(candidates)))) {
+		return errors.New("Kind window cannot be satisfied with the kinds, more kinds or a larger max are required")
+	}
+	if self.MaxWalk < 0 {
+		return errors.New("Max length of keyboard walks must not be negative")
+	}
+	if self.MaxDistinctSymbols < 0 {
+		return errors.New("Max number of distinct symbols must not be negative")
+	}
+	if entropy := Entropy(self); entropy < self.MinEntropy {
+		return errors.New(fmt.Sprintf("Entropy of passwords is %.1f bits, lower than the minimum of %.1f bits", entropy, self.MinEntropy))
+	}
+	return nil
+}
+
+func hasCandidates(kind CharacterKind, candidates []rune) bool {
+	for _, r := range candidates {
+		if kindContains(kind, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// disjointGroups counts groups sharing no characters with each other, greedily.
+// Each of them requires a character of its own, so passwords shorter than it cannot satisfy all groups.
+func disjointGroups(groups [][]rune) int {
+	used := make(map[rune]bool)
+	count := 0
+	for _, group := range groups {
+		if slices.ContainsFunc(group, func(r rune) bool { return used[r] }) {
+			continue
+		}
+		for _, r := range group {
+			used[r] = true
+		}
+		count++
+	}
+	return count
+}
@@ -0,0 +1,71 @@
+package gotpasswd
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// Validator accepts or rejects generated passwords, such as a validator of an external system.
+type Validator interface {
+	Validate(ctx context.Context, passwd string) (bool, error)
+}
+
+// CommandValidator runs a command with the password on its stdin followed by a newline, and accepts the password
+// when the command exits with zero. If "{}" is in its arguments, the password is substituted for it instead,
+// where other users can read it by ps and /proc/*/cmdline.
+type CommandValidator struct {
+	Args []string
+
+	// Exec runs the command, (*exec.Cmd).Run if nil
+	Exec func(cmd *exec.Cmd) error
+}
+
+// NewCommandValidator splits a command by white spaces, without any shell.
+func NewCommandValidator(command string) (*CommandValidator, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, errors.New("Validator command must not be empty")
+	}
+	return &CommandValidator{Args: args}, nil
+}
+
+func (self *CommandValidator) Validate(ctx context.Context, passwd string) (bool, error) {
+	args := make([]string, len(self.Args))
+	stdin := true
+	for i, arg := range self.Args {
+		args[i] = strings.ReplaceAll(arg, "{}", passwd)
+		stdin = stdin && args[i] == arg
+	}
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	if stdin {
+		cmd.Stdin = strings.NewReader(passwd + "\n")
+	}
+	run := self.Exec
+	if run == nil {
+		run = (*exec.Cmd).Run
+	}
+	err := run(cmd)
+	if _, ok := err.(*exec.ExitError); ok && ctx.Err() == nil {
+		return false, nil
+	} else if err != nil {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Validators accepts passwords accepted by all of them, in order.
+type Validators []Validator
+
+func (self Validators) Validate(ctx context.Context, passwd string) (bool, error) {
+	for _, validator := range self {
+		if ok, err := validator.Validate(ctx, passwd); !ok || err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,86 @@
+package gotpasswd
+
+import (
+	"context"
+	"io"
+	"os/exec"
+	"testing"
+)
+
+func TestCommandValidatorLoop(t *testing.T) {
+	validator, err := NewCommandValidator("check-password --strict")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the stub rejects passwords until the third one
+	var stdins []string
+	validator.Exec = func(cmd *exec.Cmd) error {
+		if len(cmd.Args) != 2 || cmd.Args[1] != "--strict" {
+			t.Errorf("args are %q", cmd.Args)
+		}
+		stdin, _ := io.ReadAll(cmd.Stdin)
+		stdins = append(stdins, string(stdin))
+		if len(stdins) < 3 {
+			return exec.Command("false").Run()
+		}
+		return nil
+	}
+	passwd, err := GenerateContext(context.Background(), &Config{
+		Kinds:     []CharacterKind{ALPHABET},
+		Length:    12,
+		Validator: validator,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stdins) != 3 {
+		t.Fatalf("validator is run %d times, expected 3", len(stdins))
+	}
+	if stdins[2] != passwd+"\n" {
+		t.Errorf("accepted password %q is not the one validated last %q", passwd, stdins[2])
+	}
+}
+
+func TestCommandValidatorArgument(t *testing.T) {
+	validator, err := NewCommandValidator("check-password {}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator.Exec = func(cmd *exec.Cmd) error {
+		if cmd.Stdin != nil {
+			t.Error("password is also given on stdin")
+		}
+		if cmd.Args[1] != "secret" {
+			return exec.Command("false").Run()
+		}
+		return nil
+	}
+	for passwd, expected := range map[string]bool{"secret": true, "other": false} {
+		ok, err := validator.Validate(context.Background(), passwd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != expected {
+			t.Errorf("%s is validated as %v", passwd, ok)
+		}
+	}
+}
+
+func TestCommandValidatorStdin(t *testing.T) {
+	if _, err := exec.LookPath("grep"); err != nil {
+		t.Skip("grep is not found")
+	}
+	validator, err := NewCommandValidator("grep -q [0-9]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for passwd, expected := range map[string]bool{"abc1": true, "abcd": false} {
+		ok, err := validator.Validate(context.Background(), passwd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != expected {
+			t.Errorf("%s is validated as %v", passwd, ok)
+		}
+	}
+}
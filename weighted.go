@@ -0,0 +1,96 @@
+package gotpasswd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type WeightedCharset struct {
+	Name   string
+	Chars  []rune
+	Weight int
+}
+
+var weightedCharsetPattern = regexp.MustCompile(`^(\w+)=(.+):(\d+)$`)
+
+// ParseWeightedCharsets parses "vowels=aeiou:3,consonants=bcdfg:7".
+// Characters can contain ',' unless it's followed by another "name=".
+func ParseWeightedCharsets(s string) ([]WeightedCharset, error) {
+	specs := make([]string, 0)
+	for _, segment := range strings.Split(s, ",") {
+		if len(specs) > 0 && !weightedCharsetPattern.MatchString(segment) {
+			specs[len(specs)-1] += "," + segment
+		} else {
+			specs = append(specs, segment)
+		}
+	}
+
+	charsets := make([]WeightedCharset, 0, len(specs))
+	for _, spec := range specs {
+		m := weightedCharsetPattern.FindStringSubmatch(spec)
+		if m == nil {
+			return nil, errors.New(fmt.Sprintf("Invalid weighted charset, must be name=chars:weight: %s", spec))
+		}
+		weight, err := strconv.Atoi(m[3])
+		if err != nil || weight <= 0 {
+			return nil, errors.New(fmt.Sprintf("Weight must be positive: %s", spec))
+		}
+		charsets = append(charsets, WeightedCharset{
+			Name:   m[1],
+			Chars:  uniqueRunes([]rune(m[2])),
+			Weight: weight,
+		})
+	}
+	return charsets, nil
+}
+
+// WeightedEntropy returns the Shannon entropy in bits per character of the weighted selection.
+// It's lower than log2 of the number of characters, since characters are no longer uniformly chosen.
+func WeightedEntropy(charsets []WeightedCharset) float64 {
+	total := 0
+	for _, charset := range charsets {
+		total += charset.Weight
+	}
+	probs := make(map[rune]float64)
+	for _, charset := range charsets {
+		for _, r := range charset.Chars {
+			probs[r] += float64(charset.Weight) / float64(total) / float64(len(charset.Chars))
+		}
+	}
+	entropy := 0.0
+	for _, p := range probs {
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+func generateWeighted(r io.Reader, charsets []WeightedCharset, length int) ([]rune, error) {
+	total := 0
+	for _, charset := range charsets {
+		total += charset.Weight
+	}
+	chars := make([]rune, length)
+	for i := range chars {
+		v, err := randomInt(r, total)
+		if err != nil {
+			return nil, err
+		}
+		for _, charset := range charsets {
+			if v < charset.Weight {
+				index, err := randomInt(r, len(charset.Chars))
+				if err != nil {
+					return nil, err
+				}
+				chars[i] = charset.Chars[index]
+				break
+			}
+			v -= charset.Weight
+		}
+	}
+	return chars, nil
+}
@@ -0,0 +1,71 @@
+package gotpasswd
+
+import (
+	"math"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+func TestWeightedProportions(t *testing.T) {
+	charsets, err := ParseWeightedCharsets("vowels=aeiou:3,consonants=bcdfg:7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a fixed seed makes the test reproducible
+	r := rand.NewChaCha8([32]byte{1})
+	const n = 10000
+	chars, err := generateWeighted(r, charsets, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[rune]int)
+	vowels := 0
+	for _, c := range chars {
+		counts[c]++
+		if strings.ContainsRune("aeiou", c) {
+			vowels++
+		}
+	}
+	// the standard deviation is sqrt(0.3*0.7/n), about 0.005
+	if p := float64(vowels) / n; math.Abs(p-0.3) > 0.02 {
+		t.Errorf("proportion of vowels = %f, want 0.3", p)
+	}
+	for _, c := range "aeioubcdfg" {
+		want := 0.3 / 5
+		if !strings.ContainsRune("aeiou", c) {
+			want = 0.7 / 5
+		}
+		if p := float64(counts[c]) / n; math.Abs(p-want) > 0.015 {
+			t.Errorf("proportion of %q = %f, want %f", c, p, want)
+		}
+	}
+	if len(counts) != 10 {
+		t.Errorf("drawn %d characters, want 10", len(counts))
+	}
+}
+
+func TestWeightedEntropy(t *testing.T) {
+	for _, c := range []struct {
+		charsets []WeightedCharset
+		want     float64
+	}{
+		{[]WeightedCharset{{Chars: []rune("ab"), Weight: 1}}, 1},
+		// 1/6, 1/6 and 2/3
+		{[]WeightedCharset{{Chars: []rune("ab"), Weight: 1}, {Chars: []rune("c"), Weight: 2}}, 2.0/6*math.Log2(6) + 2.0/3*math.Log2(1.5)},
+		// b of both charsets is 3/4
+		{[]WeightedCharset{{Chars: []rune("ab"), Weight: 1}, {Chars: []rune("b"), Weight: 1}}, 0.25*2 + 0.75*math.Log2(4.0/3)},
+	} {
+		if entropy := WeightedEntropy(c.charsets); math.Abs(entropy-c.want) > 1e-9 {
+			t.Errorf("WeightedEntropy(%v) = %f, want %f", c.charsets, entropy, c.want)
+		}
+	}
+}
+
+func TestParseWeightedCharsetsErrors(t *testing.T) {
+	for _, s := range []string{"", "vowels=aeiou", "vowels=aeiou:0", "=a:1"} {
+		if _, err := ParseWeightedCharsets(s); err == nil {
+			t.Errorf("ParseWeightedCharsets(%q) must fail", s)
+		}
+	}
+}